@@ -0,0 +1,215 @@
+package todoist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Object types of the activity log.
+const (
+	ObjectTask    = "item"
+	ObjectComment = "note"
+	ObjectProject = "project"
+)
+
+// Event types of the activity log.
+const (
+	EventAdded       = "added"
+	EventUpdated     = "updated"
+	EventCompleted   = "completed"
+	EventUncompleted = "uncompleted"
+	EventDeleted     = "deleted"
+	EventArchived    = "archived"
+	EventUnarchived  = "unarchived"
+	EventShared      = "shared"
+	EventLeft        = "left"
+)
+
+// activityLimit is the largest page of the activity log.
+const activityLimit = 100
+
+// Event is an entry of the activity log.
+type Event struct {
+	ID              ID     `json:"id"`
+	ObjectType      string `json:"object_type"`
+	ObjectID        ID     `json:"object_id"`
+	EventType       string `json:"event_type"`
+	EventDate       string `json:"event_date"`
+	ParentProjectID ID     `json:"parent_project_id"`
+	// ParentTaskID is the task of a comment or the parent of a subtask.
+	ParentTaskID ID `json:"parent_item_id"`
+	InitiatorID  ID `json:"initiator_id"`
+	// ExtraData holds details that depend on the object and event type, such
+	// as content and last_content of tasks.
+	ExtraData map[string]interface{} `json:"extra_data"`
+}
+
+func (e *Event) EventTime() time.Time {
+	time, _ := time.Parse(time.RFC3339, e.EventDate)
+	return time
+}
+
+// String describes the event, e.g. "updated task: Buy milk (was: Buy tea)".
+func (e *Event) String() string {
+	object := e.ObjectType
+	switch e.ObjectType {
+	case ObjectTask:
+		object = "task"
+	case ObjectComment:
+		object = "comment"
+	}
+
+	text := fmt.Sprintf("%s %s", e.EventType, object)
+	name, _ := e.ExtraData["content"].(string)
+	last, _ := e.ExtraData["last_content"].(string)
+	if e.ObjectType == ObjectProject {
+		name, _ = e.ExtraData["name"].(string)
+		last, _ = e.ExtraData["last_name"].(string)
+	}
+
+	if name != "" {
+		text += ": " + name
+	}
+	if last != "" && last != name {
+		text += " (was: " + last + ")"
+	}
+	return text
+}
+
+// ListActivity lists events of the activity log, newest first.
+func (c *Client) ListActivity(filter *ActivityFilter) ([]*Event, error) {
+	return c.ListActivityContext(context.Background(), filter)
+}
+
+func (c *Client) ListActivityContext(ctx context.Context, filter *ActivityFilter) ([]*Event, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	limit := 0
+	if filter != nil {
+		limit = filter.Limit
+	}
+
+	ro := NewRequestOption()
+	for k, v := range filter.args() {
+		ro.Params[k] = fmt.Sprint(v)
+	}
+
+	u := c.syncEndpoint("/activity/get")
+	if c.apiVersion == UnifiedAPI {
+		u = c.restEndpoint("/activities")
+	}
+
+	out := []*Event{}
+	for len(out) < limit || limit == 0 {
+		size := activityLimit
+		if limit != 0 && limit-len(out) < size {
+			size = limit - len(out)
+		}
+		ro.Params["limit"] = fmt.Sprint(size)
+
+		if c.apiVersion == UnifiedAPI {
+			var page struct {
+				Results    []*Event `json:"results"`
+				NextCursor string   `json:"next_cursor"`
+			}
+			resp, err := c.httpRequest(ctx, "GET", u, ro)
+			if err != nil {
+				return nil, err
+			}
+			if err := decodeJSON(resp, &page); err != nil {
+				return nil, err
+			}
+			out = append(out, page.Results...)
+
+			if page.NextCursor == "" {
+				break
+			}
+			ro.Params["cursor"] = page.NextCursor
+			continue
+		}
+
+		ro.Params["offset"] = fmt.Sprint(len(out))
+		var page struct {
+			Events []*Event `json:"events"`
+			Count  int      `json:"count"`
+		}
+		resp, err := c.httpRequest(ctx, "GET", u, ro)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeJSON(resp, &page); err != nil {
+			return nil, err
+		}
+		out = append(out, page.Events...)
+
+		if len(page.Events) == 0 || len(out) >= page.Count {
+			break
+		}
+	}
+
+	if limit != 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// ListTaskActivity lists the events of a task and its comments, newest
+// first.
+func (c *Client) ListTaskActivity(id ID, limit int) ([]*Event, error) {
+	return c.ListTaskActivityContext(context.Background(), id, limit)
+}
+
+func (c *Client) ListTaskActivityContext(ctx context.Context, id ID, limit int) ([]*Event, error) {
+	return c.listActivities(ctx, limit,
+		&ActivityFilter{ObjectType: ObjectTask, ObjectID: id, Limit: limit},
+		&ActivityFilter{ObjectType: ObjectComment, ParentTaskID: id, Limit: limit},
+	)
+}
+
+// ListProjectActivity lists the events of a project and the objects in it,
+// newest first.
+func (c *Client) ListProjectActivity(id ID, limit int) ([]*Event, error) {
+	return c.ListProjectActivityContext(context.Background(), id, limit)
+}
+
+func (c *Client) ListProjectActivityContext(ctx context.Context, id ID, limit int) ([]*Event, error) {
+	return c.listActivities(ctx, limit,
+		&ActivityFilter{ObjectType: ObjectProject, ObjectID: id, Limit: limit},
+		&ActivityFilter{ParentProjectID: id, Limit: limit},
+	)
+}
+
+// listActivities merges the events matching any of the filters.
+func (c *Client) listActivities(ctx context.Context, limit int, filters ...*ActivityFilter) ([]*Event, error) {
+	seen := map[ID]bool{}
+	out := []*Event{}
+	for _, filter := range filters {
+		events, err := c.ListActivityContext(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range events {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				out = append(out, e)
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, tj := out[i].EventTime(), out[j].EventTime()
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return out[j].ID.less(out[i].ID)
+	})
+	if limit != 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
@@ -0,0 +1,110 @@
+package todoist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestListActivity(t *testing.T) {
+	c, s := newTestClient(t)
+	project := s.AddProject("Work")
+	task := s.AddTask(todoisttest.Task{Content: "write report", ProjectID: project.ID})
+	other := s.AddTask(todoisttest.Task{Content: "call mom"})
+
+	if err := c.UpdateTaskWithParams(ID(task.ID), &UpdateTaskParams{Content: "write the report"}); err != nil {
+		t.Fatalf("Failed to update the task: %s", err)
+	}
+	if _, err := c.AddComment(&AddCommentParams{TaskID: ID(task.ID), Content: "half done"}); err != nil {
+		t.Fatalf("Failed to comment on the task: %s", err)
+	}
+	if err := c.CloseTask(ID(task.ID)); err != nil {
+		t.Fatalf("Failed to complete the task: %s", err)
+	}
+	if err := c.CloseTask(ID(other.ID)); err != nil {
+		t.Fatalf("Failed to complete the task: %s", err)
+	}
+
+	events, err := c.ListActivity(&ActivityFilter{ObjectType: ObjectTask, EventType: EventCompleted})
+	if err != nil {
+		t.Fatalf("Failed to list the activity: %s", err)
+	}
+	if len(events) != 2 || events[0].ObjectID != ID(other.ID) || events[1].ObjectID != ID(task.ID) {
+		t.Fatalf("Expected 2 completions, newest first, got %+v", events)
+	}
+
+	events, err = c.ListTaskActivity(ID(task.ID), 0)
+	if err != nil {
+		t.Fatalf("Failed to list the task activity: %s", err)
+	}
+	got := []string{}
+	for _, e := range events {
+		got = append(got, e.String())
+	}
+	want := []string{
+		"completed task: write the report",
+		"added comment: half done",
+		"updated task: write the report (was: write report)",
+		"added task: write report",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Unexpected task activity %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Unexpected task activity %q, want %q", got, want)
+		}
+	}
+
+	events, err = c.ListProjectActivity(ID(project.ID), 3)
+	if err != nil {
+		t.Fatalf("Failed to list the project activity: %s", err)
+	}
+	if len(events) != 3 || events[0].String() != "completed task: write the report" {
+		t.Fatalf("Expected the 3 latest events of the project, got %+v", events)
+	}
+
+	if events, err = c.ListActivity(&ActivityFilter{ObjectEventTypes: []string{"note:", "project:added"}}); err != nil || len(events) != 3 {
+		t.Fatalf("Expected 2 projects and a comment added, got %v, %+v", err, events)
+	}
+}
+
+func TestListActivityPaging(t *testing.T) {
+	c, s := newTestClient(t)
+	for i := 0; i < 2*activityLimit+10; i++ {
+		s.AddTask(todoisttest.Task{Content: "task"})
+	}
+
+	events, err := c.ListActivity(&ActivityFilter{ObjectType: ObjectTask})
+	if err != nil {
+		t.Fatalf("Failed to list the activity: %s", err)
+	}
+	if len(events) != 2*activityLimit+10 {
+		t.Fatalf("Expected %d events, got %d", 2*activityLimit+10, len(events))
+	}
+	if n := countRequests(s, "GET", "/sync/v8/activity/get"); n != 3 {
+		t.Fatalf("Expected 3 page requests, got %d", n)
+	}
+
+	if events, err = c.ListActivity(&ActivityFilter{Limit: 150}); err != nil || len(events) != 150 {
+		t.Fatalf("Expected 150 events, got %v, %d", err, len(events))
+	}
+}
+
+func TestUnifiedActivity(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	project := s.AddProject("Work")
+	for i := 0; i < 3; i++ {
+		task := s.AddTask(todoisttest.Task{Content: "done", ProjectID: project.ID})
+		s.CompleteTask(task.ID, time.Now().Add(-time.Hour))
+	}
+
+	events, err := c.ListProjectActivity(ID(project.ID), 0)
+	if err != nil {
+		t.Fatalf("Failed to list the project activity: %s", err)
+	}
+	if len(events) < 3 || events[0].EventType != EventCompleted || events[0].ObjectID == "" {
+		t.Fatalf("Expected the project activity across pages, got %+v", events)
+	}
+}
@@ -2,23 +2,75 @@ package todoist
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell"
 	"github.com/rivo/tview"
 )
 
+// The sync tokens and IDs of the two API generations are not interchangeable,
+// so each keeps its own cache.
+const (
+	syncCacheFile        = "sync.json"
+	unifiedSyncCacheFile = "sync-unified.json"
+)
+
+// completedDays is how many days back the completed tasks view reaches.
+const completedDays = 7
+
+// activityEvents is the number of events shown in activity timelines.
+const activityEvents = 50
+
+// assignedToMe is the filter of the tasks assigned to the user.
+const assignedToMe = "assigned to: me"
+
+// defaultColumns are the columns of the task table unless configured
+// otherwise, and knownColumns all the columns it can show.
+var (
+	defaultColumns = []string{"ID", "DueDate", "Pri", "Project", "Content"}
+	knownColumns   = []string{"ID", "DueDate", "Pri", "Project", "Section", "Assignee", "Duration", "Deadline", "Created", "Creator", "Content", "Description"}
+)
+
 type Application struct {
 	ui     *UI
 	client *Client
 	config *Config
-
-	tasks    []*Task
-	labels   map[uint]string
-	projects map[uint]string
+	sync   *SyncEngine
+
+	// tasks holds the task of every table row, nil for section headers.
+	tasks []*Task
+	// filtered holds the rows matching the filter before subtasks are
+	// nested under their parents.
+	filtered []*Task
+	// depth and parents describe the subtask tree of the visible rows, and
+	// collapsed marks the tasks whose subtasks are hidden.
+	depth     map[ID]int
+	parents   map[ID]bool
+	collapsed map[ID]bool
+	// completed holds the rows of the completed tasks view while it is
+	// shown, nil for day headers.
+	completed []*CompletedTask
+
+	labels   map[ID]string
+	projects map[ID]string
+	sections map[ID]*Section
+	// collaborators caches the collaborators of the shared projects, and
+	// collaboratorErrs the projects whose collaborators failed to load.
+	collaborators    map[ID][]*Collaborator
+	collaboratorErrs map[ID]error
+	// columns are the columns of the task table.
+	columns []string
+
+	clientOptions []ClientOption
 }
 
-func NewApplication() (*Application, error) {
+// NewApplication creates the terminal client. The options are applied to
+// every API client it creates.
+func NewApplication(opts ...ClientOption) (*Application, error) {
 	config, err := NewConfig()
 	if err != nil {
 		return nil, err
@@ -26,14 +78,36 @@ func NewApplication() (*Application, error) {
 
 	a := &Application{
 		ui:       NewUI(),
-		client:   NewClient(config.Token),
 		config:   config,
 		tasks:    []*Task{},
-		labels:   map[uint]string{},
-		projects: map[uint]string{},
+		filtered: []*Task{},
+		labels:   map[ID]string{},
+		projects: map[ID]string{},
+		sections: map[ID]*Section{},
+		columns:  columns(config.Columns),
+
+		collaborators:    map[ID][]*Collaborator{},
+		collaboratorErrs: map[ID]error{},
+
+		depth:     map[ID]int{},
+		parents:   map[ID]bool{},
+		collapsed: map[ID]bool{},
+
+		clientOptions: opts,
+	}
+
+	a.ui.SetHeaders(a.columns)
+
+	a.connect()
+	if err := a.sync.Load(); err != nil {
+		return nil, err
 	}
 
 	a.ui.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if a.completed != nil {
+			return a.completedInput(event)
+		}
+
 		switch event.Key() {
 		case tcell.KeyEnter:
 			a.ShowDetail()
@@ -51,10 +125,39 @@ func NewApplication() (*Application, error) {
 				a.QuickFilter()
 			case 'e':
 				a.EditContent()
+			case 'E':
+				a.EditDescription()
 			case 'd':
 				a.EditDuedate()
 			case 'p':
 				a.MoveProject()
+			case 'P':
+				a.ShowProjects()
+			case 's':
+				a.MoveSection()
+			case 'l':
+				a.EditLabels()
+			case 'R':
+				_, t := a.GetSelection()
+				a.EditReminders(t)
+			case 'A':
+				a.AttachFile()
+			case 'o':
+				a.Assign()
+			case 'm':
+				if err := a.SetFilter(assignedToMe); err != nil {
+					a.handleError(err)
+				}
+			case 'N':
+				a.AddSubtask()
+			case '>':
+				a.Indent()
+			case '<':
+				a.Outdent()
+			case '+':
+				a.Expand(true)
+			case '-':
+				a.Expand(false)
 			case 'r':
 				a.Refresh()
 			case 'D':
@@ -63,6 +166,16 @@ func NewApplication() (*Application, error) {
 				a.Complete()
 			case 'u':
 				a.Reopen()
+			case 'c':
+				if err := a.ShowCompleted(); err != nil {
+					a.handleError(err)
+				}
+			case 'h':
+				a.ShowTaskActivity()
+			case 'H':
+				a.ShowProjectActivity()
+			case 'K':
+				a.ShowStats()
 			case '1':
 				a.SetPriority(4)
 			case '2':
@@ -79,8 +192,18 @@ func NewApplication() (*Application, error) {
 		return event
 	})
 
-	if err := a.Refresh(); err != nil {
-		return nil, err
+	for {
+		err := a.Refresh()
+		if err == nil {
+			break
+		}
+		if !IsUnauthorized(err) {
+			return nil, err
+		}
+
+		fmt.Println(err)
+		a.config.PromptToken()
+		a.connect()
 	}
 
 	return a, nil
@@ -94,37 +217,56 @@ func (a *Application) Stop() {
 	a.ui.Stop()
 }
 
+// connect creates the API client and a fresh sync engine for the configured
+// token.
+func (a *Application) connect() {
+	a.client = NewClient(a.config.Token, a.clientOptions...)
+	a.sync = NewSyncEngine(a.client)
+	a.sync.CacheFile = syncCacheFile
+	if a.client.APIVersion() == UnifiedAPI {
+		a.sync.CacheFile = unifiedSyncCacheFile
+	}
+}
+
 func (a *Application) Refresh() error {
-	if labels, err := a.client.ListLabels(); err != nil {
+	if err := a.sync.Sync(); err != nil {
 		return err
-	} else {
-		for _, label := range labels {
-			a.labels[label.ID] = "@" + label.Name
-		}
 	}
 
-	if projects, err := a.client.ListProjects(); err != nil {
-		return err
-	} else {
-		for _, project := range projects {
-			a.projects[project.ID] = "#" + project.Name
-		}
+	a.labels = map[ID]string{}
+	for _, label := range a.sync.Labels() {
+		a.labels[label.ID] = "@" + label.Name
 	}
 
-	if err := a.Update(); err != nil {
-		return err
+	a.projects = map[ID]string{}
+	a.sections = map[ID]*Section{}
+	a.collaborators = map[ID][]*Collaborator{}
+	a.collaboratorErrs = map[ID]error{}
+	for _, project := range a.sync.Projects() {
+		a.projects[project.ID] = "#" + project.Name
+		for _, section := range a.sync.Sections(project.ID) {
+			a.sections[section.ID] = section
+		}
 	}
-	return nil
+
+	return a.SetFilter(a.config.Filter)
 }
 
 func (a *Application) ShowHelp() {
 	var help = `       [::b]Q :[::-] Quit
        [::b]? :[::-] Help
 
-       [::b]F :[::-] Filter the list
+       [::b]F :[::-] Pick a filter, project or label
        [::b]R :[::-] Refresh the lisk
+ [::b]Shift-P :[::-] Project tree
+       [::b]C :[::-] Completed tasks
+       [::b]H :[::-] Task activity
+ [::b]Shift-H :[::-] Project activity
+ [::b]Shift-K :[::-] Productivity and karma
+       [::b]M :[::-] Tasks assigned to me
 
        [::b]A :[::-] Quick add
+ [::b]Shift-N :[::-] Add a subtask
        [::b]V :[::-] Task detail
    [::b]Enter :[::-] Task detail
 
@@ -133,9 +275,20 @@ func (a *Application) ShowHelp() {
        [::b]U :[::-] Uncomplete a task
 
        [::b]E :[::-] Edit the text
+ [::b]Shift-E :[::-] Edit the description
        [::b]P :[::-] Move the project
+       [::b]S :[::-] Move to a section
        [::b]D :[::-] Set the due date
-     [::b]1-4 :[::-] Set the priority P1 to P4`
+       [::b]L :[::-] Edit the labels
+ [::b]Shift-R :[::-] Set a reminder
+ [::b]Shift-A :[::-] Attach a file
+       [::b]O :[::-] Assign the task
+     [::b]1-4 :[::-] Set the priority P1 to P4
+
+       [::b]> :[::-] Make a subtask of the task above
+       [::b]< :[::-] Move a subtask up a level
+       [::b]+ :[::-] Show the subtasks
+       [::b]- :[::-] Hide the subtasks`
 
 	a.ui.Popup("Help", help)
 }
@@ -145,44 +298,294 @@ func (a *Application) ShowDetail() {
 
 	var b strings.Builder
 	fmt.Fprintf(&b, "[::b]Project:[-::-]  %s\n", a.project(t.ProjectID))
-	fmt.Fprintf(&b, "[::b]DueDate:[-::-]  %s\n", t.DueString())
-	fmt.Fprintf(&b, "[::b]Labels:[-::-]   %s\n", strings.Join(a.label(t.LabelIDs), ","))
+	if section, ok := a.sections[t.SectionID]; ok {
+		fmt.Fprintf(&b, "[::b]Section:[-::-]  %s\n", tview.Escape(section.Name))
+	}
+	fmt.Fprintf(&b, "[::b]DueDate:[-::-]  %s\n", t.DueStringIn(a.location()))
+	if t.AssigneeID != "" {
+		fmt.Fprintf(&b, "[::b]Assignee:[-::-] %s\n", tview.Escape(a.userName(t.ProjectID, t.AssigneeID)))
+	}
+	if t.Duration != nil {
+		fmt.Fprintf(&b, "[::b]Duration:[-::-] %s\n", t.Duration)
+	}
+	if t.Deadline != nil {
+		fmt.Fprintf(&b, "[::b]Deadline:[-::-] %s\n", deadlineString(t))
+	}
+	if reminders := a.sync.Reminders(t.ID); len(reminders) > 0 {
+		list := []string{}
+		for _, r := range reminders {
+			list = append(list, r.StringIn(a.location()))
+		}
+		fmt.Fprintf(&b, "[::b]Reminders:[-::-] %s\n", tview.Escape(strings.Join(list, ", ")))
+	}
+	fmt.Fprintf(&b, "[::b]Labels:[-::-]   %s\n", strings.Join(a.label(t), ","))
 	fmt.Fprintf(&b, "[::b]Priority:[-::-] P%d\n", 5-t.Priority)
+	if created := t.CreatedTime(); !created.IsZero() {
+		fmt.Fprintf(&b, "[::b]Created:[-::-]  %s", created.In(a.location()).Format("2006-01-02 15:04"))
+		if t.CreatorID != "" {
+			fmt.Fprintf(&b, " by %s", tview.Escape(a.userName(t.ProjectID, t.CreatorID)))
+		}
+		b.WriteString("\n")
+	}
 	fmt.Fprintf(&b, "[::b]URL:[-::-] %s\n", t.URL)
 
 	fmt.Fprintf(&b, "\n\n%s", tview.Escape(marginLink(t.Content)))
+	if t.Description != "" {
+		fmt.Fprintf(&b, "\n\n%s", tview.Escape(marginLink(t.Description)))
+	}
 
-	comments, err := a.client.ListComments(&map[string]interface{}{"task_id": t.ID})
+	comments, err := a.client.ListCommentsWithFilter(&CommentFilter{TaskID: t.ID})
 	if err != nil {
-		a.ui.ErrorMessage(err)
+		a.handleError(err)
 	} else if len(comments) > 0 {
 		fmt.Fprintf(&b, "\n\n--")
 		for _, comment := range comments {
 			fmt.Fprintf(&b, "\n%s\n%s", comment.Posted, tview.Escape(marginLink(comment.Content)))
+			if f := comment.Attachment; f != nil {
+				fmt.Fprintf(&b, "\n[::b]Attachment:[-::-] %s\n  %s", tview.Escape(f.String()), f.FileURL)
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "\n\n[::d]c: add a comment, e: edit a comment, d: edit the description, r: reminders[::-]")
+	a.ui.PopupWithActions("Detail", b.String(), map[rune]func(){
+		'c': func() { a.AddComment(t) },
+		'd': func() { a.EditDescription() },
+		'e': func() { a.EditComment(t, comments) },
+		'r': func() { a.EditReminders(t) },
+	})
+}
+
+// AddComment adds a comment to the task and shows its detail again.
+func (a *Application) AddComment(t *Task) {
+	a.ui.PopupInput("Add comment", "", func(text string) {
+		if text == "" {
+			return
+		}
+
+		if _, err := a.client.AddComment(&AddCommentParams{TaskID: t.ID, Content: text}); err != nil {
+			a.handleError(err)
+			return
+		}
+		a.ShowDetail()
+	})
+}
+
+// AttachFile uploads a local file and attaches it to the selected task.
+func (a *Application) AttachFile() {
+	r, t := a.GetSelection()
+	a.ui.PopupInput("Attach file", "", func(path string) {
+		if path == "" {
+			return
+		}
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+
+		if _, err := a.client.AttachFile(t.ID, path, ""); err != nil {
+			a.handleError(err)
+			return
+		}
+
+		t.CommentCount++
+		a.ui.RenderRow(r, a.cells(r, t)...)
+		a.ui.StatusLine(fmt.Sprintf("[black:green:b] Attached %s ", tview.Escape(filepath.Base(path))), 3*time.Second)
+	})
+}
+
+// ownComments returns the comments posted by userID and whether the poster
+// of every comment was known. REST v1 comments do not tell their poster,
+// which is then looked up among the synced notes.
+func ownComments(comments []*Comment, notes []*Note, userID ID) ([]*Comment, bool) {
+	posters := map[ID]ID{}
+	for _, n := range notes {
+		posters[n.ID] = n.PosterID
+	}
+
+	own := []*Comment{}
+	known := true
+	for _, c := range comments {
+		poster := c.PosterID
+		if poster == "" {
+			poster = posters[c.ID]
+		}
+		if poster == "" {
+			known = false
+		}
+		if userID != "" && poster == userID {
+			own = append(own, c)
+		}
+	}
+	return own, known
+}
+
+// EditComment edits one of the user's own comments among the comments of t.
+// Clearing the text deletes the comment.
+func (a *Application) EditComment(t *Task, comments []*Comment) {
+	own, known := ownComments(comments, a.sync.Notes(t.ID), a.sync.User().ID)
+	if !known {
+		// Comments added since the last sync are not among the notes yet.
+		if err := a.sync.Sync(); err != nil {
+			a.handleError(err)
+			return
 		}
+		own, _ = ownComments(comments, a.sync.Notes(t.ID), a.sync.User().ID)
+	}
+
+	items := []string{}
+	for _, c := range own {
+		items = append(items, fmt.Sprintf("%s  %s", c.Posted, sanitizeLink(c.Content)))
+	}
+
+	edit := func(c *Comment) {
+		a.ui.PopupInput("Edit comment", c.Content, func(text string) {
+			if text != "" {
+				if err := a.client.UpdateComment(c.ID, text); err != nil {
+					a.handleError(err)
+					return
+				}
+				a.ShowDetail()
+				return
+			}
+
+			a.ui.PopupConfirm("Are you sure you want to delete the comment?", []string{"Delete", "Cancel"}, func(label string) {
+				if label != "Delete" {
+					return
+				}
+				if err := a.client.DeleteComment(c.ID); err != nil {
+					a.handleError(err)
+					return
+				}
+				a.ShowDetail()
+			})
+		})
+	}
+
+	switch len(own) {
+	case 0:
+		a.ui.ErrorMessage(fmt.Errorf("No comments of yours to edit"))
+	case 1:
+		edit(own[0])
+	default:
+		a.ui.PopupList("Edit comment", items, func(i int) { edit(own[i]) })
+	}
+}
+
+// ShowProjects shows the project hierarchy with the number of active tasks
+// in each project.
+func (a *Application) ShowProjects() {
+	count := map[ID]int{}
+	for _, t := range a.sync.Tasks(nil) {
+		count[t.ProjectID]++
+	}
+
+	var b strings.Builder
+	for _, root := range ProjectTree(a.sync.Projects()) {
+		root.Walk(func(node *ProjectNode, depth int) {
+			fmt.Fprintf(&b, "%s%s [::d](%d)[::-]\n", strings.Repeat("  ", depth), tview.Escape("#"+node.Name), count[node.ID])
+		})
 	}
 
-	a.ui.Popup("Detail", b.String())
+	a.ui.Popup("Projects", strings.TrimSuffix(b.String(), "\n"))
 }
 
+// QuickFilter lets the user pick a saved filter, a project or a label with
+// the number of its tasks, type a filter query or save the current one. Only
+// the saved filters that the synced state can evaluate are counted, so that
+// opening the list sends no request.
 func (a *Application) QuickFilter() {
-	a.ui.PopupInput("Quick filter", a.config.Filter, func(text string) {
-		if err := a.SetFilter(text); err != nil {
-			a.ui.ErrorMessage(err)
+	items := []string{"Type a filter...", "Save the current filter..."}
+	queries := []string{"", ""}
+
+	for _, f := range a.sync.Filters() {
+		item := tview.Escape(f.Name)
+		if tasks, ok := a.localTasks(f.Query); ok {
+			item += fmt.Sprintf(" (%d)", countTasks(tasks))
+		}
+		items = append(items, item)
+		queries = append(queries, f.Query)
+	}
+
+	projectCount := map[ID]int{}
+	labelCount := map[string]int{}
+	for _, t := range a.sync.Tasks(nil) {
+		projectCount[t.ProjectID]++
+		for _, name := range a.label(t) {
+			labelCount[strings.ToLower(name)]++
+		}
+	}
+	for _, p := range a.sync.Projects() {
+		name := a.project(p.ID)
+		items = append(items, fmt.Sprintf("%s (%d)", tview.Escape(name), projectCount[p.ID]))
+		queries = append(queries, name)
+	}
+	for _, l := range a.sync.Labels() {
+		name := "@" + l.Name
+		items = append(items, fmt.Sprintf("%s (%d)", tview.Escape(name), labelCount[strings.ToLower(name)]))
+		queries = append(queries, name)
+	}
+
+	a.ui.PopupList("Filter", items, func(i int) {
+		switch i {
+		case 0:
+			a.ui.PopupInput("Quick filter", a.config.Filter, func(text string) {
+				if err := a.SetFilter(text); err != nil {
+					a.handleError(err)
+				}
+			})
+		case 1:
+			a.SaveFilter()
+		default:
+			if err := a.SetFilter(queries[i]); err != nil {
+				a.handleError(err)
+			}
+		}
+	})
+}
+
+// SaveFilter saves the current filter query under a name.
+func (a *Application) SaveFilter() {
+	query := a.config.Filter
+	a.ui.PopupInput(fmt.Sprintf("Save %s as", query), "", func(text string) {
+		if text == "" {
+			return
+		}
+
+		if _, err := a.client.AddFilter(&AddFilterParams{Name: text, Query: query}); err != nil {
+			a.handleError(err)
+			return
+		}
+		if err := a.sync.Sync(); err != nil {
+			a.handleError(err)
+			return
 		}
+		a.ui.StatusLine(fmt.Sprintf("[black:white:b] Saved the filter `%s` ", tview.Escape(text)), 3*time.Second)
 	})
 }
 
+// countTasks counts the tasks of filtered rows, leaving out section headers.
+func countTasks(rows []*Task) int {
+	n := 0
+	for _, t := range rows {
+		if t != nil {
+			n++
+		}
+	}
+	return n
+}
+
 func (a *Application) QuickAdd() {
 	a.ui.PopupInput("Quick add", "", func(text string) {
 		var err error
-		if err = a.client.QuickAddTask(text, nil); err != nil {
-			a.ui.ErrorMessage(err)
+		if err = a.client.QuickAddTaskWithParams(text, nil); err != nil {
+			a.handleError(err)
 			return
 		}
 
-		if err = a.Update(); err != nil {
-			a.ui.ErrorMessage(err)
+		if err = a.Refresh(); err != nil {
+			a.handleError(err)
 			return
 		}
 	})
@@ -192,18 +595,40 @@ func (a *Application) EditContent() {
 	r, t := a.GetSelection()
 	a.ui.PopupInput("Edit text", t.Content, func(text string) {
 		var err error
-		if err = a.client.UpdateTask(t.ID, &map[string]interface{}{"content": text}); err != nil {
-			a.ui.ErrorMessage(err)
+		if err = a.client.UpdateTaskWithParams(t.ID, &UpdateTaskParams{Content: text}); err != nil {
+			a.handleError(err)
 			return
 		}
 
 		if t, err = a.client.GetTask(t.ID); err != nil {
-			a.ui.ErrorMessage(err)
+			a.handleError(err)
 			return
 		}
 
-		a.tasks[r] = t
-		a.ui.RenderRow(r, a.cells(r, t)...)
+		a.updateRow(r, t)
+	})
+}
+
+// EditDescription edits the description of the selected task on one line,
+// where \n stands for a line break.
+func (a *Application) EditDescription() {
+	r, t := a.GetSelection()
+	lines := strings.NewReplacer("\\", "\\\\", "\n", "\\n")
+	a.ui.PopupInput("Edit description (\\n for a new line)", lines.Replace(t.Description), func(text string) {
+		description := strings.NewReplacer("\\\\", "\\", "\\n", "\n").Replace(text)
+
+		var err error
+		if err = a.client.UpdateTaskWithParams(t.ID, &UpdateTaskParams{Description: &description}); err != nil {
+			a.handleError(err)
+			return
+		}
+
+		if t, err = a.client.GetTask(t.ID); err != nil {
+			a.handleError(err)
+			return
+		}
+
+		a.updateRow(r, t)
 	})
 }
 
@@ -211,25 +636,33 @@ func (a *Application) EditDuedate() {
 	r, t := a.GetSelection()
 	a.ui.PopupInput("Edit due date", t.Due.String, func(text string) {
 		var err error
-		if err = a.client.UpdateTask(t.ID, &map[string]interface{}{"due_string": text}); err != nil {
-			a.ui.ErrorMessage(err)
+		if err = a.client.UpdateTaskWithParams(t.ID, dueUpdate(text)); err != nil {
+			a.handleError(err)
 			return
 		}
 
 		if t, err = a.client.GetTask(t.ID); err != nil {
-			a.ui.ErrorMessage(err)
+			a.handleError(err)
 			return
 		}
 
-		a.tasks[r] = t
-		a.ui.RenderRow(r, a.cells(r, t)...)
+		a.updateRow(r, t)
 	})
 }
 
+// dueUpdate returns the parameters setting the due date typed by the user,
+// where an empty text removes it.
+func dueUpdate(text string) *UpdateTaskParams {
+	if strings.TrimSpace(text) == "" {
+		text = NoDueDate
+	}
+	return &UpdateTaskParams{DueParams: DueParams{DueString: text}}
+}
+
 func (a *Application) MoveProject() {
 	r, t := a.GetSelection()
 	a.ui.PopupInput("Move project", a.project(t.ProjectID), func(text string) {
-		var projectID uint
+		var projectID ID
 		for k, v := range a.projects {
 			if strings.EqualFold(text, v) {
 				projectID = k
@@ -237,193 +670,1058 @@ func (a *Application) MoveProject() {
 			}
 		}
 
-		if projectID == 0 {
-			a.ui.ErrorMessage(fmt.Errorf("Invalid project name: %s", text))
+		if projectID == "" {
+			a.handleError(fmt.Errorf("Invalid project name: %s", text))
 			return
 		}
 
 		var err error
-		if err = a.client.MoveTask(t.ID, &map[string]interface{}{"project_id": projectID}); err != nil {
-			a.ui.ErrorMessage(err)
+		if err = a.client.MoveTaskWithParams(t.ID, &MoveTaskParams{ProjectID: projectID}); err != nil {
+			a.handleError(err)
 			return
 		}
 
 		if t, err = a.client.GetTask(t.ID); err != nil {
-			a.ui.ErrorMessage(err)
+			a.handleError(err)
 			return
 		}
 
-		a.tasks[r] = t
-		a.ui.RenderRow(r, a.cells(r, t)...)
+		a.updateRow(r, t)
 	})
 }
 
-func (a *Application) SetPriority(p int) {
+// EditLabels lets the user check the labels of the selected task and create
+// new ones, given as a comma or space separated list of names.
+func (a *Application) EditLabels() {
 	r, t := a.GetSelection()
 
-	var err error
-	if err = a.client.UpdateTask(t.ID, &map[string]interface{}{"priority": p}); err != nil {
-		a.ui.ErrorMessage(err)
-		return
+	labels := a.sync.Labels()
+	current := a.label(t)
+	items := make([]string, len(labels))
+	checked := make([]bool, len(labels))
+	for i, l := range labels {
+		items[i] = "@" + l.Name
+		for _, name := range current {
+			checked[i] = checked[i] || strings.EqualFold(name, items[i])
+		}
 	}
 
-	if t, err = a.client.GetTask(t.ID); err != nil {
-		a.ui.ErrorMessage(err)
-		return
-	}
+	a.ui.PopupChecklist("Edit labels", items, checked, "New labels:", func(checked []bool, text string) {
+		selected := []*Label{}
+		for i, l := range labels {
+			if checked[i] {
+				selected = append(selected, l)
+			}
+		}
 
-	a.tasks[r] = t
-	a.ui.RenderRow(r, a.cells(r, t)...)
+		for _, name := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' }) {
+			name = strings.TrimPrefix(name, "@")
+			if l := findLabel(labels, name); l != nil {
+				if findLabel(selected, name) == nil {
+					selected = append(selected, l)
+				}
+				continue
+			}
+
+			l, err := a.client.AddLabel(&AddLabelParams{Name: name})
+			if err != nil {
+				a.handleError(err)
+				return
+			}
+			a.labels[l.ID] = "@" + l.Name
+			labels = append(labels, l)
+			selected = append(selected, l)
+		}
+
+		params := &UpdateTaskParams{}
+		if a.client.APIVersion() == UnifiedAPI {
+			params.Labels = []string{}
+			for _, l := range selected {
+				params.Labels = append(params.Labels, l.Name)
+			}
+		} else {
+			params.LabelIDs = []ID{}
+			for _, l := range selected {
+				params.LabelIDs = append(params.LabelIDs, l.ID)
+			}
+		}
+
+		var err error
+		if err = a.client.UpdateTaskWithParams(t.ID, params); err != nil {
+			a.handleError(err)
+			return
+		}
+
+		if t, err = a.client.GetTask(t.ID); err != nil {
+			a.handleError(err)
+			return
+		}
+
+		a.updateRow(r, t)
+	})
 }
 
-func (a *Application) Reopen() {
-	if a.config.Closed == 0 {
-		return
+// reminderOffsets are the relative reminders offered by EditReminders, in
+// minutes before the due time.
+var reminderOffsets = []int{0, 10, 30, 60, 24 * 60}
+
+// EditReminders lets the user add a reminder to the task or remove one.
+func (a *Application) EditReminders(t *Task) {
+	reminders := a.sync.Reminders(t.ID)
+
+	items := []string{}
+	for _, offset := range reminderOffsets {
+		items = append(items, "Remind "+formatOffset(offset))
+	}
+	items = append(items, "Remind at...")
+	for _, r := range reminders {
+		items = append(items, "Remove: "+r.StringIn(a.location()))
 	}
 
-	var err error
-	if err = a.client.ReopenTask(a.config.Closed); err != nil {
-		a.ui.ErrorMessage(err)
+	a.ui.PopupList("Reminders", items, func(i int) {
+		switch {
+		case i < len(reminderOffsets):
+			a.addReminder(t, ReminderTime{Type: ReminderRelative, MinuteOffset: reminderOffsets[i]})
+		case i == len(reminderOffsets):
+			a.ui.PopupInput("Remind at", "", func(text string) {
+				if text != "" {
+					a.addReminder(t, ReminderTime{Type: ReminderAbsolute, DueString: text})
+				}
+			})
+		default:
+			r := reminders[i-len(reminderOffsets)-1]
+			if err := a.client.DeleteReminder(r.ID); err != nil {
+				a.handleError(err)
+				return
+			}
+			a.syncReminders("Removed the reminder")
+		}
+	})
+}
+
+func (a *Application) addReminder(t *Task, when ReminderTime) {
+	if _, err := a.client.AddReminder(&AddReminderParams{TaskID: t.ID, ReminderTime: when}); err != nil {
+		a.handleError(err)
 		return
 	}
+	a.syncReminders("Added the reminder")
+}
 
-	if err = a.Update(); err != nil {
-		a.ui.ErrorMessage(err)
+func (a *Application) syncReminders(message string) {
+	if err := a.sync.Sync(); err != nil {
+		a.handleError(err)
 		return
 	}
+	a.ui.StatusLine(fmt.Sprintf("[black:white:b] %s ", message), 3*time.Second)
 }
 
-func (a *Application) Complete() {
+// Assign lets the user pick a collaborator of the project to assign the
+// selected task to.
+func (a *Application) Assign() {
 	r, t := a.GetSelection()
-	if err := a.client.CloseTask(t.ID); err != nil {
-		a.ui.ErrorMessage(err)
+
+	collaborators, err := a.collaboratorsOf(t.ProjectID)
+	if err != nil {
+		a.handleError(err)
+		return
+	}
+	if len(collaborators) == 0 {
+		a.handleError(fmt.Errorf("The project is not shared: %s", a.project(t.ProjectID)))
 		return
 	}
 
-	a.config.Closed = t.ID
-	a.config.Save()
+	items := []string{"(Unassigned)"}
+	for _, c := range collaborators {
+		items = append(items, fmt.Sprintf("%s <%s>", c.Name, c.Email))
+	}
 
-	a.tasks = append(a.tasks[:r], a.tasks[r+1:]...)
-	a.ui.RemoveRow(r)
-}
+	a.ui.PopupList("Assign", items, func(i int) {
+		var id ID
+		if i > 0 {
+			id = collaborators[i-1].ID
+		}
 
-func (a *Application) Delete() {
-	r, t := a.GetSelection()
-	message := fmt.Sprintf("Are you sure you want to delete `%s`?", sanitizeLink(t.Content))
-	a.ui.PopupConfirm(message, []string{"Delete", "Cancel"}, func(text string) {
-		if text == "Delete" {
-			if err := a.client.DeleteTask(t.ID); err != nil {
-				a.ui.ErrorMessage(err)
-				return
-			}
+		var err error
+		if err = a.client.UpdateTaskWithParams(t.ID, &UpdateTaskParams{AssigneeID: &id}); err != nil {
+			a.handleError(err)
+			return
+		}
 
-			a.tasks = append(a.tasks[:r], a.tasks[r+1:]...)
-			a.ui.RemoveRow(r)
+		if t, err = a.client.GetTask(t.ID); err != nil {
+			a.handleError(err)
+			return
 		}
-	})
-}
 
-func (a *Application) GetSelection() (int, *Task) {
-	r := a.ui.GetSelection()
-	t := a.tasks[r]
-	return r, t
+		a.updateRow(r, t)
+	})
 }
 
-func (a *Application) Update() error {
-	return a.SetFilter(a.config.Filter)
-}
+// collaboratorsOf returns the collaborators of the project, fetching them
+// once per refresh. A failure is remembered until the next refresh too, so
+// that rendering rows does not send the request again for every row.
+func (a *Application) collaboratorsOf(projectID ID) ([]*Collaborator, error) {
+	if list, ok := a.collaborators[projectID]; ok {
+		return list, nil
+	}
+	if err, ok := a.collaboratorErrs[projectID]; ok {
+		return nil, err
+	}
 
-func (a *Application) SetFilter(str string) error {
-	isPremium, err := a.client.isPremium()
+	list, err := a.client.ListCollaborators(projectID)
 	if err != nil {
-		a.ui.ErrorMessage(err)
-		return err
+		a.collaboratorErrs[projectID] = err
+		return nil, err
 	}
+	a.collaborators[projectID] = list
+	return list, nil
+}
 
-	if str == "" {
-		str = "#inbox"
+// userName returns the name of a user of the project, or the ID when the
+// name is unknown.
+func (a *Application) userName(projectID, id ID) string {
+	if id == "" {
+		return ""
+	}
+	if user := a.sync.User(); user.ID == id && user.FullName != "" {
+		return user.FullName
 	}
 
-	var params map[string]interface{}
-	if isPremium {
-		params = map[string]interface{}{"filter": str}
-	} else {
-		var projectID uint
-		for k, v := range a.projects {
-			if strings.EqualFold(str, v) {
-				projectID = k
-				break
+	if list, err := a.collaboratorsOf(projectID); err == nil {
+		for _, c := range list {
+			if c.ID == id {
+				return c.Name
 			}
 		}
+	}
+	return fmt.Sprint(id)
+}
 
-		if projectID == 0 {
-			a.ui.ErrorMessage(fmt.Errorf("Invalid project name: %s", str))
-			return err
+func findLabel(labels []*Label, name string) *Label {
+	for _, l := range labels {
+		if strings.EqualFold(l.Name, name) {
+			return l
 		}
+	}
+	return nil
+}
+
+// MoveSection moves the selected task to another section of its project.
+func (a *Application) MoveSection() {
+	_, t := a.GetSelection()
 
-		params = map[string]interface{}{"project_id": projectID}
+	sections := a.sync.Sections(t.ProjectID)
+	if len(sections) == 0 {
+		a.ui.ErrorMessage(fmt.Errorf("The project has no sections"))
+		return
 	}
 
-	if a.tasks, err = a.client.ListTasks(&params); err != nil {
-		return err
+	items := []string{"(No section)"}
+	for _, section := range sections {
+		items = append(items, section.Name)
 	}
 
-	a.config.Filter = str
-	a.config.Save()
+	a.ui.PopupList("Move to section", items, func(i int) {
+		params := &MoveTaskParams{ProjectID: t.ProjectID}
+		if i > 0 {
+			params = &MoveTaskParams{SectionID: sections[i-1].ID}
+		}
 
-	a.ui.Init()
-	a.ui.FilterStatus(str)
-	for i, t := range a.tasks {
-		a.ui.RenderRow(i, a.cells(i, t)...)
+		if err := a.client.MoveTaskWithParams(t.ID, params); err != nil {
+			a.handleError(err)
+			return
+		}
+
+		if err := a.Refresh(); err != nil {
+			a.handleError(err)
+		}
+	})
+}
+
+// AddSubtask adds a task under the selected one.
+func (a *Application) AddSubtask() {
+	_, t := a.GetSelection()
+	a.ui.PopupInput("Add subtask", "", func(text string) {
+		if text == "" {
+			return
+		}
+
+		subtask, err := a.client.AddTaskWithParams(&AddTaskParams{Content: text, ParentID: t.ID})
+		if err != nil {
+			a.handleError(err)
+			return
+		}
+
+		delete(a.collapsed, t.ID)
+		if err = a.Refresh(); err != nil {
+			a.handleError(err)
+			return
+		}
+		a.selectTask(subtask.ID)
+	})
+}
+
+// Indent makes the selected task a subtask of the task above it on the same
+// level.
+func (a *Application) Indent() {
+	r, t := a.GetSelection()
+
+	var sibling *Task
+	for i := r - 1; i >= 0 && a.tasks[i] != nil && a.depth[a.tasks[i].ID] >= a.depth[t.ID]; i-- {
+		if a.depth[a.tasks[i].ID] == a.depth[t.ID] {
+			sibling = a.tasks[i]
+			break
+		}
+	}
+	if sibling == nil {
+		a.ui.ErrorMessage(fmt.Errorf("No task above to indent under"))
+		return
+	}
+
+	a.move(t, &MoveTaskParams{ParentID: sibling.ID})
+	delete(a.collapsed, sibling.ID)
+}
+
+// Outdent moves the selected subtask next to its parent.
+func (a *Application) Outdent() {
+	_, t := a.GetSelection()
+	if t.ParentID == "" {
+		a.ui.ErrorMessage(fmt.Errorf("The task is not a subtask"))
+		return
+	}
+
+	params := &MoveTaskParams{ProjectID: t.ProjectID}
+	if parent, ok := a.sync.Task(t.ParentID); ok {
+		switch {
+		case parent.ParentID != "":
+			params = &MoveTaskParams{ParentID: parent.ParentID}
+		case parent.SectionID != "":
+			params = &MoveTaskParams{SectionID: parent.SectionID}
+		}
+	}
+	a.move(t, params)
+}
+
+func (a *Application) move(t *Task, params *MoveTaskParams) {
+	if err := a.client.MoveTaskWithParams(t.ID, params); err != nil {
+		a.handleError(err)
+		return
+	}
+
+	if err := a.Refresh(); err != nil {
+		a.handleError(err)
+		return
+	}
+	a.selectTask(t.ID)
+}
+
+// Expand shows or hides the subtasks of the selected task.
+func (a *Application) Expand(expand bool) {
+	_, t := a.GetSelection()
+	if !a.parents[t.ID] {
+		return
+	}
+
+	if expand {
+		delete(a.collapsed, t.ID)
+	} else {
+		a.collapsed[t.ID] = true
+	}
+	a.render()
+	a.selectTask(t.ID)
+}
+
+func (a *Application) SetPriority(p int) {
+	r, t := a.GetSelection()
+
+	var err error
+	if err = a.client.UpdateTaskWithParams(t.ID, &UpdateTaskParams{Priority: p}); err != nil {
+		a.handleError(err)
+		return
+	}
+
+	if t, err = a.client.GetTask(t.ID); err != nil {
+		a.handleError(err)
+		return
 	}
 
+	a.updateRow(r, t)
+}
+
+func (a *Application) Reopen() {
+	if a.config.Closed == "" {
+		return
+	}
+
+	var err error
+	if err = a.client.ReopenTask(a.config.Closed); err != nil {
+		a.handleError(err)
+		return
+	}
+
+	if err = a.Refresh(); err != nil {
+		a.handleError(err)
+		return
+	}
+}
+
+// ShowTaskActivity shows the timeline of the selected task.
+func (a *Application) ShowTaskActivity() {
+	_, t := a.GetSelection()
+	events, err := a.client.ListTaskActivity(t.ID, activityEvents)
+	if err != nil {
+		a.handleError(err)
+		return
+	}
+	a.showActivity(fmt.Sprintf("Activity of %s", sanitizeLink(t.Content)), t.ProjectID, events)
+}
+
+// ShowProjectActivity shows the timeline of the project of the selected
+// task.
+func (a *Application) ShowProjectActivity() {
+	_, t := a.GetSelection()
+	events, err := a.client.ListProjectActivity(t.ProjectID, activityEvents)
+	if err != nil {
+		a.handleError(err)
+		return
+	}
+	a.showActivity(fmt.Sprintf("Activity of %s", a.project(t.ProjectID)), t.ProjectID, events)
+}
+
+func (a *Application) showActivity(title string, projectID ID, events []*Event) {
+	if len(events) == 0 {
+		a.ui.Popup(title, "No activity")
+		return
+	}
+
+	var b strings.Builder
+	for _, e := range events {
+		fmt.Fprintf(&b, "[::b]%s[::-]  %s", e.EventTime().In(a.location()).Format("2006-01-02 15:04"), tview.Escape(e.String()))
+		if e.InitiatorID != "" && e.InitiatorID != a.sync.User().ID {
+			project := e.ParentProjectID
+			if project == "" {
+				project = projectID
+			}
+			fmt.Fprintf(&b, " [gray]by %s[-]", tview.Escape(a.userName(project, e.InitiatorID)))
+		}
+		b.WriteString("\n")
+	}
+	a.ui.Popup(title, strings.TrimSuffix(b.String(), "\n"))
+}
+
+// ShowStats shows the karma, the progress towards the goals and charts of
+// the tasks completed in the recent days and weeks.
+func (a *Application) ShowStats() {
+	stats, err := a.client.GetStats()
+	if err != nil {
+		a.handleError(err)
+		return
+	}
+
+	goals := stats.Goals
+	var b strings.Builder
+	fmt.Fprintf(&b, "[::b]Karma:[::-]     %.0f (%s)\n", stats.Karma, stats.KarmaTrend)
+	fmt.Fprintf(&b, "[::b]Completed:[::-] %d tasks\n\n", stats.CompletedCount)
+	if len(stats.Days) > 0 {
+		fmt.Fprintf(&b, "[::b]Today:[::-]     %d/%d", stats.Days[0].TotalCompleted, goals.DailyGoal)
+	}
+	fmt.Fprintf(&b, "   [::b]Streak:[::-] %s (longest %s)\n", plural(goals.CurrentDailyStreak.Count, "day"), plural(goals.MaxDailyStreak.Count, "day"))
+	if len(stats.Weeks) > 0 {
+		fmt.Fprintf(&b, "[::b]This week:[::-] %d/%d", stats.Weeks[0].TotalCompleted, goals.WeeklyGoal)
+	}
+	fmt.Fprintf(&b, "   [::b]Streak:[::-] %s (longest %s)\n", plural(goals.CurrentWeeklyStreak.Count, "week"), plural(goals.MaxWeeklyStreak.Count, "week"))
+
+	counts := []int{}
+	days := []string{}
+	for i := len(stats.Days) - 1; i >= 0; i-- {
+		counts = append(counts, stats.Days[i].TotalCompleted)
+		if t, err := time.Parse("2006-01-02", stats.Days[i].Date); err == nil {
+			days = append(days, t.Format("Mon")[:2])
+		}
+	}
+	fmt.Fprintf(&b, "\n[::b]Last %d days[::-]\n  %s\n  %s\n", len(counts), sparkline(counts), strings.Join(days, ""))
+
+	max := goals.WeeklyGoal
+	for _, w := range stats.Weeks {
+		if w.TotalCompleted > max {
+			max = w.TotalCompleted
+		}
+	}
+	fmt.Fprintf(&b, "\n[::b]Last %d weeks[::-]\n", len(stats.Weeks))
+	for i := len(stats.Weeks) - 1; i >= 0; i-- {
+		w := stats.Weeks[i]
+		fmt.Fprintf(&b, "  %s %s %d\n", w.From, bar(w.TotalCompleted, max, 30), w.TotalCompleted)
+	}
+
+	a.ui.Popup("Productivity", strings.TrimSuffix(b.String(), "\n"))
+}
+
+// sparkline draws the values as a line of block characters, two columns
+// per value.
+func sparkline(values []int) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		r := ' '
+		if v > 0 {
+			r = blocks[(v*(len(blocks)-1)+max-1)/max]
+		}
+		b.WriteRune(r)
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// bar draws value as a horizontal bar of at most width columns for max.
+func bar(value, max, width int) string {
+	n := 0
+	if max > 0 {
+		n = value * width / max
+	}
+	return strings.Repeat("█", n) + strings.Repeat("░", width-n)
+}
+
+// completedInput handles the keys of the completed tasks view.
+func (a *Application) completedInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		a.HideCompleted()
+		return nil
+	}
+
+	switch event.Rune() {
+	case 'c':
+		a.HideCompleted()
+	case 'r':
+		if err := a.ShowCompleted(); err != nil {
+			a.handleError(err)
+		}
+	case 'u':
+		a.ReopenCompleted()
+	case 'q':
+		a.Stop()
+	}
+	return event
+}
+
+// ShowCompleted lists the tasks completed in the last week by day.
+func (a *Application) ShowCompleted() error {
+	tasks, err := a.client.ListCompletedTasks(&CompletedFilter{Since: time.Now().AddDate(0, 0, -completedDays)})
+	if err != nil {
+		return err
+	}
+
+	a.completed = []*CompletedTask{}
+	for i, t := range tasks {
+		if i == 0 || a.completedDay(t) != a.completedDay(tasks[i-1]) {
+			a.completed = append(a.completed, nil)
+		}
+		a.completed = append(a.completed, t)
+	}
+
+	a.ui.Init()
+	a.ui.FilterStatus("Completed (u: reopen, c: back)")
+	for i, t := range a.completed {
+		if t == nil {
+			a.ui.RenderHeader(i, a.completedDay(a.completed[i+1]))
+			continue
+		}
+		a.ui.RenderRow(i, a.completedCells(t)...)
+	}
 	return nil
 }
 
-func (a *Application) cells(r int, t *Task) []*tview.TableCell {
-	var c *tview.TableCell
+// HideCompleted goes back from the completed tasks to the filtered ones.
+func (a *Application) HideCompleted() {
+	a.completed = nil
+	a.ui.FilterStatus(a.config.Filter)
+	a.render()
+}
+
+// ReopenCompleted reopens the selected completed task.
+func (a *Application) ReopenCompleted() {
+	r := a.ui.GetSelection()
+	if r < 0 || r >= len(a.completed) || a.completed[r] == nil {
+		return
+	}
+
+	t := a.completed[r]
+	if err := a.client.ReopenTask(t.TaskID); err != nil {
+		a.handleError(err)
+		return
+	}
+	if err := a.Refresh(); err != nil {
+		a.handleError(err)
+		return
+	}
+	if err := a.ShowCompleted(); err != nil {
+		a.handleError(err)
+		return
+	}
+	a.ui.StatusLine(fmt.Sprintf("[black:white:b] Reopened `%s` ", tview.Escape(sanitizeLink(t.Content))), 3*time.Second)
+}
+
+func (a *Application) completedDay(t *CompletedTask) string {
+	return t.CompletedTime().In(a.location()).Format("2006-01-02(Mon)")
+}
+
+func (a *Application) completedCells(t *CompletedTask) []*tview.TableCell {
 	cells := []*tview.TableCell{}
+	for _, column := range a.columns {
+		c := tview.NewTableCell("")
+		switch column {
+		case "ID":
+			c.SetText(fmt.Sprint(t.TaskID))
+		case "DueDate":
+			c.SetText(t.CompletedTime().In(a.location()).Format("15:04"))
+		case "Project":
+			c.SetText(a.project(t.ProjectID)).SetMaxWidth(16)
+		case "Section":
+			if section, ok := a.sections[t.SectionID]; ok {
+				c.SetText(section.Name).SetMaxWidth(16)
+			}
+		case "Content":
+			c.SetText(sanitizeLink(t.Content)).SetTextColor(tcell.ColorGray)
+		}
+		cells = append(cells, c)
+	}
+	return cells
+}
 
-	c = tview.NewTableCell(fmt.Sprint(t.ID))
-	cells = append(cells, c)
+func (a *Application) Complete() {
+	r, t := a.GetSelection()
+	if err := a.client.CloseTask(t.ID); err != nil {
+		a.handleError(err)
+		return
+	}
+
+	a.config.Closed = t.ID
+	a.config.Save()
+
+	a.removeTask(r, t)
+}
+
+func (a *Application) Delete() {
+	r, t := a.GetSelection()
+	message := fmt.Sprintf("Are you sure you want to delete `%s`?", sanitizeLink(t.Content))
+	a.ui.PopupConfirm(message, []string{"Delete", "Cancel"}, func(text string) {
+		if text == "Delete" {
+			if err := a.client.DeleteTask(t.ID); err != nil {
+				a.handleError(err)
+				return
+			}
+
+			a.removeTask(r, t)
+		}
+	})
+}
 
-	c = tview.NewTableCell(t.DueString())
-	cells = append(cells, c)
+func (a *Application) handleError(err error) {
 	switch {
-	case t.IsOverdue():
-		c.SetTextColor(tcell.ColorRed)
-	case t.IsDuedate():
-		c.SetTextColor(tcell.ColorIndianRed)
+	case IsUnauthorized(err):
+		a.ui.PopupInput("API token rejected, input a new one", "", func(text string) {
+			if text == "" {
+				return
+			}
+
+			a.config.Token = text
+			a.config.Save()
+			a.connect()
+
+			if err := a.Refresh(); err != nil {
+				a.handleError(err)
+			}
+		})
+	case IsNotFound(err):
+		a.ui.ErrorMessage(fmt.Errorf("Not found on the server, refreshing the list"))
+		if err := a.Refresh(); err != nil && !IsNotFound(err) {
+			a.handleError(err)
+		}
+	default:
+		a.ui.ErrorMessage(err)
+	}
+}
+
+func (a *Application) GetSelection() (int, *Task) {
+	r := a.ui.GetSelection()
+	t := a.tasks[r]
+	return r, t
+}
+
+// updateRow replaces the task shown in row r.
+func (a *Application) updateRow(r int, t *Task) {
+	for i, v := range a.filtered {
+		if v != nil && v.ID == t.ID {
+			a.filtered[i] = t
+		}
+	}
+
+	a.tasks[r] = t
+	a.ui.RenderRow(r, a.cells(r, t)...)
+}
+
+// removeTask removes the task shown in row r together with its subtasks,
+// which the server completes or deletes along with it.
+func (a *Application) removeTask(r int, t *Task) {
+	removed := map[ID]bool{t.ID: true}
+	for _, root := range TaskTree(a.sync.Tasks(nil)) {
+		root.Walk(func(node *TaskNode, depth int) {
+			if removed[node.ParentID] {
+				removed[node.ID] = true
+			}
+		})
+	}
+
+	filtered := []*Task{}
+	for _, v := range a.filtered {
+		if v == nil || !removed[v.ID] {
+			filtered = append(filtered, v)
+		}
+	}
+	a.filtered = filtered
+
+	a.render()
+	if r >= len(a.tasks) {
+		r = len(a.tasks) - 1
+	}
+	for ; r >= 0; r-- {
+		if a.tasks[r] != nil {
+			a.ui.Select(r)
+			break
+		}
+	}
+}
+
+// selectTask selects the row of the task, if it is shown.
+func (a *Application) selectTask(id ID) {
+	for i, t := range a.tasks {
+		if t != nil && t.ID == id {
+			a.ui.Select(i)
+			return
+		}
+	}
+}
+
+func (a *Application) SetFilter(str string) error {
+	if str == "" {
+		str = "#inbox"
+	}
+
+	tasks, err := a.filterTasks(str)
+	if err != nil {
+		return err
+	}
+	if tasks == nil {
+		a.handleError(fmt.Errorf("Invalid project name: %s", str))
+		return nil
+	}
+	a.filtered = tasks
+	a.completed = nil
+
+	a.config.Filter = str
+	a.config.Save()
+
+	a.ui.FilterStatus(str)
+	a.render()
+
+	return nil
+}
+
+// render shows the filtered tasks with subtasks nested under their parents.
+func (a *Application) render() {
+	a.tasks = a.nest(a.filtered)
+
+	a.ui.Init()
+	for i, t := range a.tasks {
+		if t == nil {
+			a.ui.RenderHeader(i, a.sections[a.tasks[i+1].SectionID].Name)
+			continue
+		}
+		a.ui.RenderRow(i, a.cells(i, t)...)
+	}
+}
+
+// nest arranges each group of rows between section headers as a tree,
+// leaving out the subtasks of collapsed tasks and headers without tasks.
+func (a *Application) nest(rows []*Task) []*Task {
+	a.depth = map[ID]int{}
+	a.parents = map[ID]bool{}
+
+	nested := []*Task{}
+	for len(rows) > 0 {
+		header := rows[0] == nil
+		if header {
+			rows = rows[1:]
+		}
+
+		n := 0
+		for n < len(rows) && rows[n] != nil {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		if header {
+			nested = append(nested, nil)
+		}
+
+		for _, root := range TaskTree(rows[:n]) {
+			a.walk(root, 0, &nested)
+		}
+		rows = rows[n:]
+	}
+	return nested
+}
+
+func (a *Application) walk(node *TaskNode, depth int, rows *[]*Task) {
+	*rows = append(*rows, node.Task)
+	a.depth[node.ID] = depth
+	a.parents[node.ID] = len(node.Children) > 0
+
+	if a.collapsed[node.ID] {
+		return
+	}
+	for _, child := range node.Children {
+		a.walk(child, depth+1, rows)
+	}
+}
+
+// filterTasks reads project and label filters from the synced state and
+// leaves other filter queries to the server, which requires premium. It
+// returns nil tasks when the filter cannot be evaluated.
+func (a *Application) filterTasks(str string) ([]*Task, error) {
+	if tasks, ok := a.localTasks(str); ok {
+		return tasks, nil
+	}
+	if str == assignedToMe {
+		return nil, fmt.Errorf("The user is not synced yet")
+	}
+
+	if !a.sync.User().IsPremium {
+		return nil, nil
 	}
 
-	c = tview.NewTableCell("")
-	cells = append(cells, c)
-	switch t.Priority {
-	case 4:
-		c.SetText("P1").SetTextColor(tcell.ColorRed)
-	case 3:
-		c.SetText("P2").SetTextColor(tcell.ColorIndianRed)
-	case 2:
-		c.SetText("P3").SetTextColor(tcell.ColorDarkRed)
+	return a.client.ListTasksWithFilter(&TaskFilter{Filter: str})
+}
+
+// localTasks evaluates the filters that the synced state can answer without
+// a request, and reports whether str was one of them.
+func (a *Application) localTasks(str string) ([]*Task, bool) {
+	if str == assignedToMe {
+		me := a.sync.User().ID
+		if me == "" {
+			return nil, false
+		}
+		return a.sync.Tasks(func(t *Task) bool { return t.AssigneeID == me }), true
 	}
 
-	c = tview.NewTableCell(a.project(t.ProjectID)).SetMaxWidth(16)
-	cells = append(cells, c)
+	if strings.HasPrefix(str, "##") {
+		if ids := a.subprojects(str[1:]); ids != nil {
+			return a.groupBySection(a.sync.Tasks(func(t *Task) bool { return ids[t.ProjectID] })), true
+		}
+	}
+
+	for k, v := range a.projects {
+		if strings.EqualFold(str, v) {
+			return a.groupBySection(a.sync.Tasks(func(t *Task) bool { return t.ProjectID == k })), true
+		}
+	}
 
-	c = tview.NewTableCell(sanitizeLink(t.Content))
-	cells = append(cells, c)
+	for k, v := range a.labels {
+		if strings.EqualFold(str, v) {
+			return a.sync.Tasks(func(t *Task) bool {
+				for _, labelID := range t.LabelIDs {
+					if labelID == k {
+						return true
+					}
+				}
+				for _, name := range t.Labels {
+					if strings.EqualFold("@"+name, v) {
+						return true
+					}
+				}
+				return false
+			}), true
+		}
+	}
+
+	return nil, false
+}
+
+// groupBySection orders the tasks of each project by section and puts a nil
+// entry, rendered as the section header, before the tasks of every section.
+func (a *Application) groupBySection(tasks []*Task) []*Task {
+	projectRank := map[ID]int{}
+	for _, t := range tasks {
+		if _, ok := projectRank[t.ProjectID]; !ok {
+			projectRank[t.ProjectID] = len(projectRank)
+		}
+	}
+
+	sectionRank := func(t *Task) uint {
+		if section, ok := a.sections[t.SectionID]; ok {
+			return section.Order + 1
+		}
+		return 0
+	}
 
+	sort.SliceStable(tasks, func(i, j int) bool {
+		pi, pj := projectRank[tasks[i].ProjectID], projectRank[tasks[j].ProjectID]
+		if pi != pj {
+			return pi < pj
+		}
+		return sectionRank(tasks[i]) < sectionRank(tasks[j])
+	})
+
+	rows := []*Task{}
+	for i, t := range tasks {
+		if _, ok := a.sections[t.SectionID]; ok && (i == 0 || tasks[i-1].SectionID != t.SectionID) {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, t)
+	}
+	return rows
+}
+
+// subprojects returns the IDs of the named project and its descendants, or
+// nil when there is no such project.
+func (a *Application) subprojects(name string) map[ID]bool {
+	var ids map[ID]bool
+	for _, root := range ProjectTree(a.sync.Projects()) {
+		root.Walk(func(node *ProjectNode, depth int) {
+			if ids == nil && strings.EqualFold(name, a.project(node.ID)) {
+				ids = map[ID]bool{}
+				node.Walk(func(node *ProjectNode, depth int) { ids[node.ID] = true })
+			}
+		})
+	}
+	return ids
+}
+
+// columns returns the configured columns, leaving out unknown ones, or the
+// default columns if none are configured.
+func columns(names []string) []string {
+	list := []string{}
+	for _, name := range names {
+		for _, column := range knownColumns {
+			if strings.EqualFold(name, column) {
+				list = append(list, column)
+				break
+			}
+		}
+	}
+
+	if len(list) == 0 {
+		return defaultColumns
+	}
+	return list
+}
+
+func (a *Application) cells(r int, t *Task) []*tview.TableCell {
+	now := time.Now().In(a.location())
+
+	cells := []*tview.TableCell{}
+	for _, column := range a.columns {
+		c := tview.NewTableCell("")
+		cells = append(cells, c)
+
+		switch column {
+		case "ID":
+			c.SetText(fmt.Sprint(t.ID))
+		case "DueDate":
+			c.SetText(t.DueStringIn(now.Location()))
+			switch {
+			case t.IsOverdueAt(now):
+				c.SetTextColor(tcell.ColorRed)
+			case t.IsDuedateAt(now):
+				c.SetTextColor(tcell.ColorIndianRed)
+			}
+		case "Pri":
+			switch t.Priority {
+			case 4:
+				c.SetText("P1").SetTextColor(tcell.ColorRed)
+			case 3:
+				c.SetText("P2").SetTextColor(tcell.ColorIndianRed)
+			case 2:
+				c.SetText("P3").SetTextColor(tcell.ColorDarkRed)
+			}
+		case "Project":
+			c.SetText(a.project(t.ProjectID)).SetMaxWidth(16)
+		case "Section":
+			if section, ok := a.sections[t.SectionID]; ok {
+				c.SetText(section.Name).SetMaxWidth(16)
+			}
+		case "Assignee":
+			c.SetText(a.userName(t.ProjectID, t.AssigneeID)).SetMaxWidth(16)
+		case "Duration":
+			if t.Duration != nil {
+				c.SetText(t.Duration.String())
+			}
+		case "Deadline":
+			if t.Deadline != nil {
+				c.SetText(deadlineString(t))
+				if t.Deadline.Date < now.Format("2006-01-02") {
+					c.SetTextColor(tcell.ColorRed)
+				}
+			}
+		case "Created":
+			if created := t.CreatedTime(); !created.IsZero() {
+				c.SetText(created.In(now.Location()).Format("2006-01-02"))
+			}
+		case "Creator":
+			c.SetText(a.userName(t.ProjectID, t.CreatorID)).SetMaxWidth(16)
+		case "Description":
+			c.SetText(strings.Replace(sanitizeLink(t.Description), "\n", " ", -1)).SetMaxWidth(40)
+		case "Content":
+			content := strings.Repeat("  ", a.depth[t.ID]) + sanitizeLink(t.Content)
+			switch {
+			case a.parents[t.ID] && a.collapsed[t.ID]:
+				content += " ▸"
+			case a.parents[t.ID]:
+				content += " ▾"
+			}
+			c.SetText(content)
+		}
+	}
 	return cells
 }
 
-func (a *Application) project(projectID uint) string {
+// location returns the timezone due dates are shown in: the configured one,
+// else the one of the Todoist account, else the local one.
+func (a *Application) location() *time.Location {
+	for _, name := range []string{a.config.Timezone, a.sync.User().TZInfo.Timezone} {
+		if loc := loadLocation(name); loc != nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+func deadlineString(t *Task) string {
+	if d, err := time.Parse("2006-01-02", t.Deadline.Date); err == nil {
+		return d.Format("2006-01-02(Mon)")
+	}
+	return t.Deadline.Date
+}
+
+func (a *Application) project(projectID ID) string {
 	return a.projects[projectID]
 }
 
-func (a *Application) label(labelIDs []uint) []string {
+func (a *Application) label(t *Task) []string {
 	list := []string{}
-	for _, labelID := range labelIDs {
+	for _, labelID := range t.LabelIDs {
 		list = append(list, a.labels[labelID])
 	}
+	for _, name := range t.Labels {
+		list = append(list, "@"+name)
+	}
 	return list
 }
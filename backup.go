@@ -0,0 +1,93 @@
+package todoist
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Backup is one of the daily backups Todoist keeps of the account.
+type Backup struct {
+	// Version is when the backup was made, e.g. "2019-05-01 02:03" in UTC.
+	Version string `json:"version"`
+	// URL is where the zip archive is downloaded from. It carries a token,
+	// so it must not be shared.
+	URL string `json:"url"`
+}
+
+func (b *Backup) Time() time.Time {
+	time, _ := time.Parse("2006-01-02 15:04", b.Version)
+	return time
+}
+
+// ListBackups lists the backups of the account, newest first.
+func (c *Client) ListBackups() ([]*Backup, error) {
+	return c.ListBackupsContext(context.Background())
+}
+
+func (c *Client) ListBackupsContext(ctx context.Context) ([]*Backup, error) {
+	u := c.syncEndpoint("/backups/get")
+	if c.apiVersion == UnifiedAPI {
+		u = c.restEndpoint("/backups")
+	}
+
+	resp, err := c.httpRequest(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []*Backup{}
+	if err := decodeJSON(resp, &out); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time().After(out[j].Time()) })
+	return out, nil
+}
+
+// DownloadBackup saves the zip archive of the backup to path. The file is
+// only created once the whole archive has been received. A slow download is
+// not cut off by the timeout of the HTTP client unless it stalls for that
+// long.
+func (c *Client) DownloadBackup(backup *Backup, path string) error {
+	return c.DownloadBackupContext(context.Background(), backup, path)
+}
+
+func (c *Client) DownloadBackupContext(ctx context.Context, backup *Backup, path string) error {
+	u, err := url.Parse(backup.URL)
+	if err != nil || !u.IsAbs() {
+		return invalid("url", "backup has no download URL")
+	}
+
+	ro := NewRequestOption()
+	ro.KeepQuery = true
+	ro.Redact = true
+	ro.Stream = true
+
+	resp, err := c.httpRequest(ctx, "GET", u, ro)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("Failed to download the backup %s: %s", backup.Version, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
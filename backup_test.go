@@ -0,0 +1,133 @@
+package todoist
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackups(t *testing.T) {
+	c, s := newTestClient(t)
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	f, _ := zw.Create("Inbox.csv")
+	f.Write([]byte("TYPE,CONTENT\ntask,Buy milk\n"))
+	zw.Close()
+
+	s.AddBackup("2019-05-01 02:03", []byte("old"))
+	latest := s.AddBackup("2019-05-02 02:03", archive.Bytes())
+
+	backups, err := c.ListBackups()
+	if err != nil {
+		t.Fatalf("Failed to list backups: %s", err)
+	}
+	if len(backups) != 2 || backups[0].Version != latest.Version || backups[0].Time().Day() != 2 {
+		t.Fatalf("Expected the newest backup first, got %+v", backups)
+	}
+
+	var logged bytes.Buffer
+	c.Logger = log.New(&logged, "", 0)
+
+	path := filepath.Join(t.TempDir(), "backup.zip")
+	if err := c.DownloadBackup(backups[0], path); err != nil {
+		t.Fatalf("Failed to download the backup: %s", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil || !bytes.Equal(data, archive.Bytes()) {
+		t.Fatalf("Expected the archive to be saved, got %v", err)
+	}
+
+	token := backups[0].URL[strings.Index(backups[0].URL, "token=")+len("token="):]
+	forged := &Backup{Version: "2019-05-03 02:03", URL: strings.Replace(backups[0].URL, token, "forged", 1)}
+	missing := filepath.Join(t.TempDir(), "forged.zip")
+	err = c.DownloadBackup(forged, missing)
+	if err == nil {
+		t.Fatalf("Expected an error downloading with a wrong token")
+	}
+	if _, statErr := os.Stat(missing); !os.IsNotExist(statErr) {
+		t.Fatalf("Expected no file for a failed download, got %v", statErr)
+	}
+	if strings.Contains(err.Error(), "forged") {
+		t.Fatalf("Expected the error to leave out the token, got %s", err)
+	}
+
+	if !strings.Contains(logged.String(), "GET ") {
+		t.Fatalf("Expected the downloads to be logged")
+	}
+	if strings.Contains(logged.String(), token) || strings.Contains(logged.String(), "forged") {
+		t.Fatalf("Expected the log to leave out the token, got %s", logged.String())
+	}
+
+	signed := &Backup{Version: backups[0].Version, URL: backups[0].URL + "&part=2&part=1&sig=a%2Fb"}
+	var query string
+	s.Hook = func(w http.ResponseWriter, r *http.Request) bool {
+		query = r.URL.RawQuery
+		return false
+	}
+	if err := c.DownloadBackup(signed, path); err != nil {
+		t.Fatalf("Failed to download the backup: %s", err)
+	}
+	if want := signed.URL[strings.Index(signed.URL, "?")+1:]; query != want {
+		t.Fatalf("Expected the signed query %q to be kept, got %q", want, query)
+	}
+	s.Hook = nil
+
+	if err := c.DownloadBackup(&Backup{Version: "2019-05-03 02:03"}, path); err == nil {
+		t.Fatalf("Expected an error for a backup without URL")
+	}
+}
+
+func TestSlowBackup(t *testing.T) {
+	c, s := newTestClient(t)
+	c.HTTPClient.Timeout = 100 * time.Millisecond
+	backup := s.AddBackup("2019-05-01 02:03", []byte("archive"))
+
+	serve := func(pause time.Duration) func(w http.ResponseWriter, r *http.Request) bool {
+		return func(w http.ResponseWriter, r *http.Request) bool {
+			if !strings.Contains(r.URL.Path, "/backups/") {
+				return false
+			}
+			for i := 0; i < 6; i++ {
+				w.Write([]byte("part"))
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+					return true
+				case <-time.After(pause):
+				}
+			}
+			return true
+		}
+	}
+	s.Hook = serve(30 * time.Millisecond)
+
+	path := filepath.Join(t.TempDir(), "backup.zip")
+	if err := c.DownloadBackup(&Backup{Version: backup.Version, URL: backup.URL}, path); err != nil {
+		t.Fatalf("Expected a download slower than the timeout to succeed: %s", err)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil || len(data) != 24 {
+		t.Fatalf("Expected the whole download to be saved, got %v, %q", err, data)
+	}
+
+	s.Hook = serve(300 * time.Millisecond)
+	if err := c.DownloadBackup(&Backup{Version: backup.Version, URL: backup.URL}, path); err == nil {
+		t.Fatal("Expected a stalled download to time out")
+	}
+}
+
+func TestUnifiedBackups(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+
+	s.AddBackup("2019-05-01 02:03", []byte("archive"))
+	if backups, err := c.ListBackups(); err != nil || len(backups) != 1 || backups[0].URL == "" {
+		t.Fatalf("Failed to list backups: %v, %+v", err, backups)
+	}
+}
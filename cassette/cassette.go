@@ -0,0 +1,350 @@
+// Package cassette provides an http.RoundTripper that records HTTP
+// interactions to a file and replays them later.
+//
+// Plug a Transport into the http.Client of a todoist.Client to capture the
+// exact payloads of a session, e.g. to reproduce a bug report, and replay
+// them in tests without network access. API tokens and the query of signed
+// URLs are redacted from headers, URLs and bodies before anything is
+// written.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const redacted = "[REDACTED]"
+
+// Mode selects whether a Transport records or replays.
+type Mode int
+
+const (
+	ModeRecord Mode = iota
+	ModeReplay
+)
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction is a recorded request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+
+	replayed bool
+}
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Load reads a cassette file.
+func Load(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cassette{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("cassette: failed to parse %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to a file.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Transport records or replays HTTP interactions.
+type Transport struct {
+	Mode Mode
+	// Path is the cassette file. A recording Transport rewrites it after
+	// every interaction so nothing is lost if the program crashes.
+	Path string
+	// Transport sends the requests being recorded. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+	// signed holds the URLs redacted from recorded bodies, so requests to
+	// them are recorded as they will be replayed.
+	signed map[string]bool
+}
+
+// NewRecorder returns a Transport that sends requests through rt and records
+// them to path. A nil rt uses http.DefaultTransport.
+func NewRecorder(path string, rt http.RoundTripper) *Transport {
+	return &Transport{
+		Mode:      ModeRecord,
+		Path:      path,
+		Transport: rt,
+		cassette:  &Cassette{},
+	}
+}
+
+// NewReplayer returns a Transport that answers requests from the cassette
+// at path without touching the network.
+func NewReplayer(path string) (*Transport, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transport{
+		Mode:     ModeReplay,
+		Path:     path,
+		cassette: c,
+	}, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Mode == ModeReplay {
+		return t.replay(req)
+	}
+	return t.record(req)
+}
+
+func (t *Transport) record(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	if body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	rt := t.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.signed == nil {
+		t.signed = make(map[string]bool)
+	}
+
+	u := redactURL(req.URL)
+	if t.signed[req.URL.String()] {
+		u = redactQuery(req.URL)
+	}
+
+	i := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    u,
+			Header: redactHeader(req.Header),
+			Body:   redactBody(req.Header.Get("Content-Type"), body, t.signed),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       redactBody(resp.Header.Get("Content-Type"), respBody, t.signed),
+		},
+	}
+
+	t.cassette.Interactions = append(t.cassette.Interactions, i)
+	if err := t.cassette.Save(t.Path); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// replay answers with the first interaction not replayed yet that has the
+// same method and URL. Bodies are not compared since Sync API commands
+// carry random UUIDs.
+func (t *Transport) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	u := redactURL(req.URL)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, i := range t.cassette.Interactions {
+		if i.replayed || i.Request.Method != req.Method || i.Request.URL != u {
+			continue
+		}
+
+		i.replayed = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header,
+			Body:          ioutil.NopCloser(bytes.NewBufferString(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("cassette: no recorded response for %s %s", req.Method, u)
+}
+
+// Remaining returns the number of recorded interactions not replayed yet.
+func (t *Transport) Remaining() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, i := range t.cassette.Interactions {
+		if !i.replayed {
+			n++
+		}
+	}
+	return n
+}
+
+// readBody consumes and closes the request body.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	defer req.Body.Close()
+	return ioutil.ReadAll(req.Body)
+}
+
+func redactHeader(header http.Header) http.Header {
+	h := http.Header{}
+	for k, v := range header {
+		h[k] = v
+	}
+	if h.Get("Authorization") != "" {
+		h.Set("Authorization", "Bearer "+redacted)
+	}
+	return h
+}
+
+func redactURL(u *url.URL) string {
+	v := *u
+	q := v.Query()
+	if q.Get("token") != "" {
+		q.Set("token", redacted)
+		v.RawQuery = q.Encode()
+	}
+	return v.String()
+}
+
+// redactQuery replaces every value in the query of u, for signed URLs whose
+// parameters are all part of the credential.
+func redactQuery(u *url.URL) string {
+	v := *u
+	q := v.Query()
+	for k := range q {
+		q[k] = []string{redacted}
+	}
+	v.RawQuery = q.Encode()
+	return v.String()
+}
+
+// redactBody redacts the token of a form-encoded body, and every token and
+// the query of every url in a JSON body. The redacted urls are added to
+// signed.
+func redactBody(contentType string, body []byte, signed map[string]bool) string {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil || form.Get("token") == "" {
+			return string(body)
+		}
+		form.Set("token", redacted)
+		return form.Encode()
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return string(body)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || !redactJSON(v, signed) {
+		return string(body)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return string(body)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// redactJSON redacts v in place and reports whether anything was changed.
+func redactJSON(v interface{}, signed map[string]bool) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				changed = redactJSON(e, signed) || changed
+				continue
+			}
+
+			switch k {
+			case "token":
+				if s != "" {
+					v[k] = redacted
+					changed = true
+				}
+			case "url":
+				if u, err := url.Parse(s); err == nil && u.RawQuery != "" {
+					signed[s] = true
+					v[k] = redactQuery(u)
+					changed = true
+				}
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			changed = redactJSON(e, signed) || changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,137 @@
+package cassette_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haccht/todoist"
+	"github.com/haccht/todoist/cassette"
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	s := todoisttest.NewServer()
+	s.Token = "secret-token"
+	s.AddTask(todoisttest.Task{Content: "recorded"})
+
+	recorder := cassette.NewRecorder(path, nil)
+	c := todoist.NewClient(s.Token,
+		todoist.WithRESTBaseURL(s.RESTURL()),
+		todoist.WithHTTPClient(&http.Client{Transport: recorder}),
+	)
+
+	if _, err := c.AddTask(&map[string]interface{}{"content": "added"}); err != nil {
+		t.Fatalf("Failed to create a task: %s", err)
+	}
+	recorded, err := c.ListTasks(nil)
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %s", err)
+	}
+	s.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read the cassette: %s", err)
+	}
+	if strings.Contains(string(data), s.Token) {
+		t.Fatal("Expected the token to be redacted from the cassette")
+	}
+
+	replayer, err := cassette.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("Failed to load the cassette: %s", err)
+	}
+	c = todoist.NewClient("another-token",
+		todoist.WithRESTBaseURL(s.RESTURL()),
+		todoist.WithHTTPClient(&http.Client{Transport: replayer}),
+		todoist.WithRetryPolicy(todoist.NoRetry),
+	)
+
+	if _, err := c.AddTask(&map[string]interface{}{"content": "added"}); err != nil {
+		t.Fatalf("Failed to replay task creation: %s", err)
+	}
+	replayed, err := c.ListTasks(nil)
+	if err != nil {
+		t.Fatalf("Failed to replay the task list: %s", err)
+	}
+
+	if len(replayed) != len(recorded) || replayed[1].Content != "added" {
+		t.Fatalf("Expected the recorded tasks, got %+v", replayed)
+	}
+	if n := replayer.Remaining(); n != 0 {
+		t.Fatalf("Expected every interaction to be replayed, %d left", n)
+	}
+
+	if _, err := c.ListTasks(nil); err == nil {
+		t.Fatal("Expected an error once the cassette is exhausted")
+	}
+}
+
+func TestRecordRedaction(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cassette.json")
+
+	s := todoisttest.NewServer()
+	s.Token = "secret-token"
+	s.AddTask(todoisttest.Task{Content: "synced"})
+	s.AddBackup("2019-05-01 02:03", []byte("archive"))
+
+	recorder := cassette.NewRecorder(path, nil)
+	c := todoist.NewClient(s.Token,
+		todoist.WithRESTBaseURL(s.RESTURL()),
+		todoist.WithSyncBaseURL(s.SyncURL()),
+		todoist.WithHTTPClient(&http.Client{Transport: recorder}),
+	)
+
+	if err := todoist.NewSyncEngine(c).Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	backups, err := c.ListBackups()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Failed to list backups: %v, %+v", err, backups)
+	}
+	if err := c.DownloadBackup(backups[0], filepath.Join(dir, "recorded.zip")); err != nil {
+		t.Fatalf("Failed to download the backup: %s", err)
+	}
+	s.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read the cassette: %s", err)
+	}
+	for _, secret := range []string{s.Token, "backup-token"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("Expected %q to be redacted from the cassette", secret)
+		}
+	}
+
+	replayer, err := cassette.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("Failed to load the cassette: %s", err)
+	}
+	c = todoist.NewClient("another-token",
+		todoist.WithRESTBaseURL(s.RESTURL()),
+		todoist.WithSyncBaseURL(s.SyncURL()),
+		todoist.WithHTTPClient(&http.Client{Transport: replayer}),
+		todoist.WithRetryPolicy(todoist.NoRetry),
+	)
+
+	e := todoist.NewSyncEngine(c)
+	if err := e.Sync(); err != nil || len(e.Tasks(nil)) != 1 {
+		t.Fatalf("Failed to replay the sync: %v", err)
+	}
+	if backups, err = c.ListBackups(); err != nil || len(backups) != 1 {
+		t.Fatalf("Failed to replay the backups: %v, %+v", err, backups)
+	}
+	if err := c.DownloadBackup(backups[0], filepath.Join(dir, "replayed.zip")); err != nil {
+		t.Fatalf("Failed to replay the download: %s", err)
+	}
+	if n := replayer.Remaining(); n != 0 {
+		t.Fatalf("Expected every interaction to be replayed, %d left", n)
+	}
+}
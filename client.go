@@ -2,6 +2,7 @@ package todoist
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,34 +11,136 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"reflect"
+	"time"
+)
+
+const (
+	todoistSyncAPI    = "https://api.todoist.com/sync/v8"
+	todoistRESTAPI    = "https://api.todoist.com/rest/v1"
+	todoistUnifiedAPI = "https://api.todoist.com/api/v1"
 
-	"github.com/google/uuid"
+	pageLimit = 200
+
+	defaultTimeout = 30 * time.Second
 )
 
+// APIVersion selects the generation of the Todoist API a Client talks to.
+type APIVersion int
+
 const (
-	todoistSyncAPI = "https://api.todoist.com/sync/v8"
-	todoistRESTAPI = "https://api.todoist.com/rest/v1"
+	// LegacyAPI is the REST v1 and Sync v8 generation with numeric IDs.
+	LegacyAPI APIVersion = iota
+	// UnifiedAPI is the unified API v1 with string IDs and cursor-paginated
+	// list endpoints.
+	UnifiedAPI
 )
 
 type Client struct {
-	authToken string
+	authToken   string
+	apiVersion  APIVersion
+	restBaseURL string
+	syncBaseURL string
+	userAgent   string
+
+	Logger      *log.Logger
+	HTTPClient  *http.Client
+	RetryPolicy RetryPolicy
+}
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithAPIVersion selects the API generation. Base URLs not set explicitly
+// default to the official endpoints of that generation.
+func WithAPIVersion(version APIVersion) ClientOption {
+	return func(c *Client) {
+		c.apiVersion = version
+	}
+}
 
-	Logger     *log.Logger
-	HTTPClient *http.Client
+// WithRESTBaseURL points the client at an alternative REST API root,
+// such as a local stand-in server or a proxy.
+func WithRESTBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.restBaseURL = baseURL
+	}
+}
+
+// WithSyncBaseURL points the client at an alternative Sync API root.
+func WithSyncBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.syncBaseURL = baseURL
+	}
 }
 
-func NewClient(authToken string) *Client {
-	return &Client{
-		authToken:  authToken,
-		Logger:     log.New(ioutil.Discard, "", log.LstdFlags),
-		HTTPClient: http.DefaultClient,
+// WithHTTPClient replaces the http.Client used to send requests.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+func NewClient(authToken string, opts ...ClientOption) *Client {
+	c := &Client{
+		authToken:   authToken,
+		Logger:      log.New(ioutil.Discard, "", log.LstdFlags),
+		HTTPClient:  &http.Client{Timeout: defaultTimeout},
+		RetryPolicy: DefaultRetryPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	switch {
+	case c.restBaseURL != "":
+	case c.apiVersion == UnifiedAPI:
+		c.restBaseURL = todoistUnifiedAPI
+	default:
+		c.restBaseURL = todoistRESTAPI
 	}
+
+	switch {
+	case c.syncBaseURL != "":
+	case c.apiVersion == UnifiedAPI:
+		c.syncBaseURL = todoistUnifiedAPI
+	default:
+		c.syncBaseURL = todoistSyncAPI
+	}
+
+	return c
+}
+
+func (c *Client) APIVersion() APIVersion {
+	return c.apiVersion
 }
 
 type RequestOption struct {
 	Params  map[string]string
 	Headers map[string]string
 	Body    io.Reader
+
+	// Idempotent marks a request as safe to retry even though its method is
+	// not, e.g. read-only or UUID-tagged Sync API calls.
+	Idempotent bool
+	// Redact keeps the query of the URL out of the log, for URLs that carry
+	// credentials.
+	Redact bool
+	// KeepQuery sends the query of the URL as it is instead of building it
+	// from Params, for signed URLs whose query must not be reencoded.
+	KeepQuery bool
+	// Stream marks a download whose body may take longer to read than the
+	// timeout of the HTTP client. The timeout then limits the wait for the
+	// response and for each part of the body instead of the whole request.
+	Stream bool
 }
 
 func NewRequestOption() *RequestOption {
@@ -47,17 +150,23 @@ func NewRequestOption() *RequestOption {
 	}
 }
 
-func restEndpoint(elm ...interface{}) *url.URL {
-	u, _ := url.ParseRequestURI(todoistRESTAPI)
-	for _, v := range elm {
-		u.Path = path.Join(u.Path, fmt.Sprint(v))
+// logURL returns u as it is written to the log.
+func (ro *RequestOption) logURL(u *url.URL) string {
+	if !ro.Redact {
+		return u.String()
 	}
 
-	return u
+	v := *u
+	v.RawQuery = ""
+	return v.String()
 }
 
-func syncEndpoint(elm ...interface{}) *url.URL {
-	u, _ := url.ParseRequestURI(todoistSyncAPI)
+func endpoint(baseURL string, elm ...interface{}) *url.URL {
+	u, err := url.ParseRequestURI(baseURL)
+	if err != nil {
+		u = &url.URL{Path: baseURL}
+	}
+
 	for _, v := range elm {
 		u.Path = path.Join(u.Path, fmt.Sprint(v))
 	}
@@ -65,27 +174,12 @@ func syncEndpoint(elm ...interface{}) *url.URL {
 	return u
 }
 
-type command struct {
-	Type   string      `json:"type"`
-	Args   interface{} `json:"args"`
-	UUID   string      `json:"uuid"`
-	TempID string      `json:"temp_id"`
+func (c *Client) restEndpoint(elm ...interface{}) *url.URL {
+	return endpoint(c.restBaseURL, elm...)
 }
 
-func makeCommand(typeString string, args interface{}) string {
-	c := command{
-		Type:   typeString,
-		Args:   args,
-		UUID:   uuid.New().String(),
-		TempID: uuid.New().String(),
-	}
-
-	commandData, err := json.Marshal([]command{c})
-	if err != nil {
-		return ""
-	}
-
-	return string(commandData)
+func (c *Client) syncEndpoint(elm ...interface{}) *url.URL {
+	return endpoint(c.syncBaseURL, elm...)
 }
 
 func decodeJSON(resp *http.Response, out interface{}) error {
@@ -95,61 +189,224 @@ func decodeJSON(resp *http.Response, out interface{}) error {
 	return dec.Decode(out)
 }
 
-func (c *Client) isPremium() (bool, error) {
-	params := url.Values{}
-	params.Add("sync_token", "*")
-	params.Add("resource_types", "[\"user\"]")
+// list fetches a list endpoint into out, a pointer to a slice. The unified
+// API wraps lists in pages, which are followed through next_cursor until
+// the last one.
+func (c *Client) list(ctx context.Context, u *url.URL, ro *RequestOption, out interface{}) error {
+	if c.apiVersion != UnifiedAPI {
+		resp, err := c.httpRequest(ctx, "GET", u, ro)
+		if err != nil {
+			return err
+		}
+		return decodeJSON(resp, out)
+	}
 
+	if ro == nil {
+		ro = NewRequestOption()
+	}
+	if _, ok := ro.Params["limit"]; !ok {
+		ro.Params["limit"] = fmt.Sprint(pageLimit)
+	}
+
+	list := reflect.ValueOf(out).Elem()
+	for {
+		resp, err := c.httpRequest(ctx, "GET", u, ro)
+		if err != nil {
+			return err
+		}
+
+		var page struct {
+			Results    json.RawMessage `json:"results"`
+			NextCursor string          `json:"next_cursor"`
+		}
+		if err := decodeJSON(resp, &page); err != nil {
+			return err
+		}
+
+		results := reflect.New(list.Type())
+		if err := json.Unmarshal(page.Results, results.Interface()); err != nil {
+			return err
+		}
+		list.Set(reflect.AppendSlice(list, results.Elem()))
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		ro.Params["cursor"] = page.NextCursor
+	}
+}
+
+// favoriteArgs renames the favorite argument of projects and labels, which
+// the unified API calls is_favorite.
+func (c *Client) favoriteArgs(args map[string]interface{}) map[string]interface{} {
+	if v, ok := args["favorite"]; ok && c.apiVersion == UnifiedAPI {
+		delete(args, "favorite")
+		args["is_favorite"] = v
+	}
+	return args
+}
+
+// assigneeArgs renames the assignee argument of tasks, which the unified API
+// calls assignee_id.
+func (c *Client) assigneeArgs(args map[string]interface{}) map[string]interface{} {
+	if v, ok := args["assignee"]; ok && c.apiVersion == UnifiedAPI {
+		delete(args, "assignee")
+		args["assignee_id"] = v
+	}
+	return args
+}
+
+// sync posts form-encoded params to the Sync API endpoint. Read requests and
+// commands tagged with UUIDs are safe to repeat, so they are retried.
+func (c *Client) sync(ctx context.Context, params url.Values, out interface{}) error {
 	ro := NewRequestOption()
 	ro.Body = bytes.NewBufferString(params.Encode())
 	ro.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+	ro.Idempotent = true
 
-	resp, err := c.httpRequest("POST", syncEndpoint("/sync"), ro)
+	resp, err := c.httpRequest(ctx, "POST", c.syncEndpoint("/sync"), ro)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	var out interface{}
-	err = decodeJSON(resp, &out)
-	if err != nil {
-		return false, err
+	if out == nil {
+		resp.Body.Close()
+		return nil
 	}
-
-	isPremium, _ := out.(map[string]interface{})["user"].(map[string]interface{})["is_premium"].(bool)
-	return isPremium, nil
+	return decodeJSON(resp, out)
 }
 
-func (c *Client) httpRequest(method string, u *url.URL, ro *RequestOption) (*http.Response, error) {
+func (c *Client) httpRequest(ctx context.Context, method string, u *url.URL, ro *RequestOption) (*http.Response, error) {
 	if ro == nil {
 		ro = NewRequestOption()
 	}
 
-	var params = make(url.Values)
-	for k, v := range ro.Params {
-		params.Add(k, v)
+	if !ro.KeepQuery {
+		var params = make(url.Values)
+		for k, v := range ro.Params {
+			params.Add(k, v)
+		}
+		u.RawQuery = params.Encode()
+	}
+
+	var body []byte
+	if ro.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(ro.Body); err != nil {
+			return nil, err
+		}
 	}
-	u.RawQuery = params.Encode()
 
-	req, err := http.NewRequest(method, u.String(), ro.Body)
+	retryable := isIdempotent(method, ro)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doRequest(ctx, method, u, ro, body)
+		if err == nil && 200 <= resp.StatusCode && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		wait, retry := c.RetryPolicy.next(attempt, resp, err)
+		if !retryable || !retry || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+
+			defer resp.Body.Close()
+			message, _ := ioutil.ReadAll(resp.Body)
+
+			endpoint := *u
+			endpoint.RawQuery = ""
+			return nil, newAPIError(method, endpoint.String(), ro.Headers["X-Request-Id"], resp, message)
+		}
+
+		reason := fmt.Sprint(err)
+		if err == nil {
+			reason = resp.Status
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		c.Logger.Printf("%s %s failed (%s), retrying in %s [attempt %d/%d]", method, ro.logURL(u), reason, wait, attempt+1, c.RetryPolicy.MaxAttempts)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Client) doRequest(ctx context.Context, method string, u *url.URL, ro *RequestOption, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, u.String(), r)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.authToken))
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 	for k, v := range ro.Headers {
 		req.Header.Set(k, v)
 	}
 
-	c.Logger.Printf("%s %s", method, u.String())
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
+	httpClient := c.HTTPClient
+	var idle *idleTimeout
+	if ro.Stream && httpClient.Timeout > 0 {
+		v := *httpClient
+		v.Timeout = 0
+		httpClient = &v
+
+		ctx, cancel := context.WithCancel(ctx)
+		req = req.WithContext(ctx)
+		idle = &idleTimeout{timeout: c.HTTPClient.Timeout, cancel: cancel}
+		idle.timer = time.AfterFunc(idle.timeout, cancel)
+	}
+
+	c.Logger.Printf("%s %s", method, ro.logURL(u))
+	resp, err := httpClient.Do(req)
+	if ue, ok := err.(*url.Error); ok && ro.Redact {
+		ue.URL = ro.logURL(u)
 	}
+	if idle != nil {
+		if err != nil {
+			idle.stop()
+			return nil, err
+		}
+		idle.ReadCloser = resp.Body
+		resp.Body = idle
+	}
+	return resp, err
+}
 
-	if resp.StatusCode < 200 || 300 <= resp.StatusCode {
-		message, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("%s: %s", resp.Status, message)
+// idleTimeout cancels a streamed request when no part of its body arrives
+// within timeout.
+type idleTimeout struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+}
+
+func (t *idleTimeout) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		t.timer.Reset(t.timeout)
 	}
+	return n, err
+}
+
+func (t *idleTimeout) Close() error {
+	err := t.ReadCloser.Close()
+	t.stop()
+	return err
+}
 
-	return resp, nil
+func (t *idleTimeout) stop() {
+	t.timer.Stop()
+	t.cancel()
 }
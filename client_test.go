@@ -0,0 +1,208 @@
+package todoist
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func countRequests(s *todoisttest.Server, method, path string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if r.Method == method && r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestHTTPRequestFailure(t *testing.T) {
+	c, s := newTestClient(t)
+	s.InjectFailure(todoisttest.Failure{Method: "GET", Path: "/rest/v1/projects", StatusCode: 400, Body: "boom", Times: 1})
+
+	if _, err := c.ListProjects(); err == nil {
+		t.Fatal("Expected an error from the injected failure")
+	}
+	if n := countRequests(s, "GET", "/rest/v1/projects"); n != 1 {
+		t.Fatalf("Expected a client error not to be retried, got %d requests", n)
+	}
+
+	if _, err := c.ListProjects(); err != nil {
+		t.Fatalf("Failed to list projects after the injected failure: %s", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	c, s := newTestClient(t)
+	s.InjectFailure(todoisttest.Failure{Method: "GET", Path: "/rest/v1/labels", StatusCode: 503, Times: 2})
+
+	if _, err := c.ListLabels(); err != nil {
+		t.Fatalf("Failed to list labels with retries: %s", err)
+	}
+	if n := countRequests(s, "GET", "/rest/v1/labels"); n != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	c, s := newTestClient(t)
+	s.InjectFailure(todoisttest.Failure{Method: "GET", Path: "/rest/v1/labels", StatusCode: 429})
+
+	if _, err := c.ListLabels(); err == nil {
+		t.Fatal("Expected an error after exhausting retries")
+	}
+	if n := countRequests(s, "GET", "/rest/v1/labels"); n != c.RetryPolicy.MaxAttempts {
+		t.Fatalf("Expected %d attempts, got %d", c.RetryPolicy.MaxAttempts, n)
+	}
+}
+
+func TestRetryIdempotency(t *testing.T) {
+	c, s := newTestClient(t)
+	s.InjectFailure(todoisttest.Failure{Method: "POST", StatusCode: 503, Times: 1})
+
+	if err := c.QuickAddTask("not retried", nil); err == nil {
+		t.Fatal("Expected quick add not to be retried")
+	}
+
+	s.InjectFailure(todoisttest.Failure{Method: "POST", StatusCode: 503, Times: 1})
+	if _, err := c.AddTask(&map[string]interface{}{"content": "retried"}); err != nil {
+		t.Fatalf("Expected a request with X-Request-Id to be retried: %s", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	tests := []struct {
+		header string
+		wait   time.Duration
+		retry  bool
+	}{
+		{"", time.Second, true},
+		{"0", 0, true},
+		{"5", 5 * time.Second, true},
+		{"60", 0, false},
+		{"invalid", time.Second, true},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: 429, Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+
+		wait, retry := policy.next(1, resp, nil)
+		if wait != tt.wait || retry != tt.retry {
+			t.Errorf("Retry-After %q: got (%s, %t), want (%s, %t)", tt.header, wait, retry, tt.wait, tt.retry)
+		}
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	c, s := newTestClient(t)
+	s.Hook = func(w http.ResponseWriter, r *http.Request) bool {
+		<-r.Context().Done()
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.ListTasksContext(ctx, nil); err == nil {
+		t.Fatal("Expected the request to be cancelled by the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the request to stop at the deadline, took %s", elapsed)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	c, s := newTestClient(t)
+
+	_, err := c.GetTask("1")
+	if !IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %T", err)
+	}
+	if apiErr.Method != "GET" || apiErr.Endpoint != s.RESTURL()+"/tasks/1" {
+		t.Fatalf("Unexpected request in the error: %s %s", apiErr.Method, apiErr.Endpoint)
+	}
+
+	s.InjectFailure(todoisttest.Failure{Method: "POST", StatusCode: 400, Body: `{"error": "Invalid argument value", "error_code": 20}`, Times: 1})
+	_, err = c.AddTask(&map[string]interface{}{"content": "rejected"})
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %T", err)
+	}
+	if apiErr.RequestID == "" || apiErr.Message != "Invalid argument value" || apiErr.ErrorCode != 20 {
+		t.Fatalf("Failed to parse the error body: %+v", apiErr)
+	}
+
+	c = NewClient("invalid", WithRESTBaseURL(s.RESTURL()))
+	if _, err := c.ListLabels(); !IsUnauthorized(err) {
+		t.Fatalf("Expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestUnifiedAPI(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	project := s.AddProject("Work")
+	s.AddLabel("urgent")
+	for i := 0; i < 4; i++ {
+		s.AddTask(todoisttest.Task{Content: "task", ProjectID: project.ID})
+	}
+
+	list, err := c.ListTasks(nil)
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %s", err)
+	}
+	if len(list) != 4 {
+		t.Fatalf("Expected 4 tasks across pages, got %d", len(list))
+	}
+	if n := countRequests(s, "GET", "/api/v1/tasks"); n != 2 {
+		t.Fatalf("Expected 2 page requests, got %d", n)
+	}
+
+	item, err := c.AddTaskWithParams(&AddTaskParams{Content: "labelled", Labels: []string{"urgent"}})
+	if err != nil {
+		t.Fatalf("Failed to create a task: %s", err)
+	}
+	if item.ID == "" {
+		t.Fatal("Expected the created task to have an ID")
+	}
+	if len(item.Labels) != 1 || item.Labels[0] != "urgent" {
+		t.Fatalf("Expected label names, got %v", item.Labels)
+	}
+
+	if got, err := c.GetTask(item.ID); err != nil || got.Content != "labelled" {
+		t.Fatalf("Failed to get the task: %v, %+v", err, got)
+	}
+
+	if err := c.QuickAddTask("write report #Work", nil); err != nil {
+		t.Fatalf("Failed to quick add a task: %s", err)
+	}
+
+	filtered, err := c.ListTasksWithFilter(&TaskFilter{Filter: "#Work"})
+	if err != nil {
+		t.Fatalf("Failed to filter tasks: %s", err)
+	}
+	if len(filtered) != 5 {
+		t.Fatalf("Expected 5 tasks in #Work, got %d", len(filtered))
+	}
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if n := len(e.Tasks(nil)); n != 6 {
+		t.Fatalf("Expected 6 synced tasks, got %d", n)
+	}
+}
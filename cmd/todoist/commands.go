@@ -0,0 +1,224 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haccht/todoist"
+)
+
+// errUsage is returned by commands called with the wrong arguments.
+var errUsage = errors.New("invalid arguments")
+
+type command struct {
+	usage string
+	run   func(c *todoist.Client, args []string) error
+}
+
+// commands are run instead of the terminal UI when named on the command line.
+var commands = map[string]command{
+	"activity": {"activity task|project ID", activity},
+	"attach":   {"attach TASK_ID FILE [COMMENT]", attach},
+	"backup":   {"backup [DIR]", backup},
+	"stats":    {"stats", stats},
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [command]\n\nFlags:\n", os.Args[0])
+	flag.PrintDefaults()
+
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(out, "\nCommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n", commands[name].usage)
+	}
+}
+
+func runCommand(args []string, opts []todoist.ClientOption) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		flag.Usage()
+		return fmt.Errorf("Unknown command: %s", args[0])
+	}
+
+	config, err := todoist.NewConfig()
+	if err != nil {
+		return err
+	}
+
+	c := todoist.NewClient(config.Token, opts...)
+	if err := cmd.run(c, args[1:]); err != errUsage {
+		return err
+	}
+	return fmt.Errorf("Usage: %s %s", os.Args[0], cmd.usage)
+}
+
+func attach(c *todoist.Client, args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return errUsage
+	}
+
+	var content string
+	if len(args) == 3 {
+		content = args[2]
+	}
+
+	comment, err := c.AttachFile(todoist.ID(args[0]), args[1], content)
+	if err != nil {
+		return err
+	}
+
+	if comment.Attachment != nil {
+		fmt.Println(comment.Attachment.FileURL)
+	}
+	return nil
+}
+
+func activity(c *todoist.Client, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	var events []*todoist.Event
+	var err error
+	switch args[0] {
+	case "task":
+		events, err = c.ListTaskActivity(todoist.ID(args[1]), 0)
+	case "project":
+		events, err = c.ListProjectActivity(todoist.ID(args[1]), 0)
+	default:
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+
+	names := initiatorNames(c)
+	for _, e := range events {
+		fmt.Printf("%s  %s", e.EventTime().Local().Format("2006-01-02 15:04"), e)
+		if e.InitiatorID != "" {
+			fmt.Printf("  by %s", names(e))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// initiatorNames returns a function naming the user who caused an event,
+// looked up among the collaborators of its project, or the user ID when the
+// name is unknown.
+func initiatorNames(c *todoist.Client) func(e *todoist.Event) string {
+	names := map[todoist.ID]string{}
+	loaded := map[todoist.ID]bool{}
+
+	return func(e *todoist.Event) string {
+		projectID := e.ParentProjectID
+		if projectID == "" && e.ObjectType == todoist.ObjectProject {
+			projectID = e.ObjectID
+		}
+
+		if !loaded[projectID] && projectID != "" {
+			loaded[projectID] = true
+			if list, err := c.ListCollaborators(projectID); err == nil {
+				for _, u := range list {
+					names[u.ID] = u.Name
+				}
+			}
+		}
+
+		if name, ok := names[e.InitiatorID]; ok {
+			return name
+		}
+		return fmt.Sprint(e.InitiatorID)
+	}
+}
+
+// stats prints the productivity stats as JSON.
+func stats(c *todoist.Client, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	stats, err := c.GetStats()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(stats)
+}
+
+// backup downloads the latest backup into DIR, the current directory by
+// default, and checks that the archive can be read.
+func backup(c *todoist.Client, args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	backups, err := c.ListBackups()
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		return errors.New("No backups available")
+	}
+
+	latest := backups[0]
+	name := "todoist-" + strings.NewReplacer(" ", "_", ":", "").Replace(latest.Version) + ".zip"
+	path := filepath.Join(dir, name)
+	if err := c.DownloadBackup(latest, path); err != nil {
+		return err
+	}
+
+	n, err := verifyZip(path)
+	if err != nil {
+		return fmt.Errorf("The backup %s is not a readable zip: %s", path, err)
+	}
+
+	fmt.Printf("%s (%d files)\n", path, n)
+	return nil
+}
+
+// verifyZip reads every file of the archive, which checks their checksums,
+// and returns the number of files.
+func verifyZip(path string) (int, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return 0, err
+		}
+		_, err = io.Copy(ioutil.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %s", f.Name, err)
+		}
+	}
+	return len(r.File), nil
+}
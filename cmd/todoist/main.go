@@ -1,13 +1,46 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"net/http"
+	"time"
 
 	"github.com/haccht/todoist"
+	"github.com/haccht/todoist/cassette"
 )
 
 func main() {
-	app, err := todoist.NewApplication()
+	record := flag.String("record", "", "record API traffic to a cassette `file`")
+	replay := flag.String("replay", "", "replay API traffic from a cassette `file`")
+	unified := flag.Bool("unified", false, "use the unified Todoist API v1")
+	flag.Usage = usage
+	flag.Parse()
+
+	var opts []todoist.ClientOption
+	if *unified {
+		opts = append(opts, todoist.WithAPIVersion(todoist.UnifiedAPI))
+	}
+	switch {
+	case *record != "":
+		recorder := cassette.NewRecorder(*record, nil)
+		opts = append(opts, todoist.WithHTTPClient(&http.Client{Transport: recorder, Timeout: 30 * time.Second}))
+	case *replay != "":
+		replayer, err := cassette.NewReplayer(*replay)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, todoist.WithHTTPClient(&http.Client{Transport: replayer}))
+	}
+
+	if flag.NArg() > 0 {
+		if err := runCommand(flag.Args(), opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	app, err := todoist.NewApplication(opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
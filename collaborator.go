@@ -0,0 +1,23 @@
+package todoist
+
+import (
+	"context"
+)
+
+// Collaborator is a user a project is shared with.
+type Collaborator struct {
+	ID    ID     `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// ListCollaborators lists the users a project is shared with, including the
+// owner.
+func (c *Client) ListCollaborators(projectID ID) ([]*Collaborator, error) {
+	return c.ListCollaboratorsContext(context.Background(), projectID)
+}
+
+func (c *Client) ListCollaboratorsContext(ctx context.Context, projectID ID) ([]*Collaborator, error) {
+	out := []*Collaborator{}
+	return out, c.list(ctx, c.restEndpoint("/projects", projectID, "/collaborators"), nil, &out)
+}
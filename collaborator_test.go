@@ -0,0 +1,69 @@
+package todoist
+
+import (
+	"testing"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestCollaborators(t *testing.T) {
+	c, s := newTestClient(t)
+	project := s.AddProject("Shared")
+	alice := s.AddCollaborator(project.ID, "Alice", "alice@example.com")
+	bob := s.AddCollaborator(s.AddProject("Other").ID, "Bob", "bob@example.com")
+
+	list, err := c.ListCollaborators(ID(project.ID))
+	if err != nil {
+		t.Fatalf("Failed to list collaborators: %s", err)
+	}
+	if len(list) != 2 || list[0].ID != ID(todoisttest.UserID) || list[1].Name != "Alice" || list[1].Email != "alice@example.com" {
+		t.Fatalf("Expected the owner and Alice, got %+v", list)
+	}
+
+	task, err := c.AddTaskWithParams(&AddTaskParams{Content: "review", ProjectID: ID(project.ID), AssigneeID: ID(alice.ID)})
+	if err != nil {
+		t.Fatalf("Failed to add an assigned task: %s", err)
+	}
+	if task.AssigneeID != ID(alice.ID) || task.AssignerID != ID(todoisttest.UserID) {
+		t.Fatalf("Expected the task assigned to Alice by the user, got %+v", task)
+	}
+
+	bobID := ID(bob.ID)
+	if err := c.UpdateTaskWithParams(task.ID, &UpdateTaskParams{AssigneeID: &bobID}); err == nil {
+		t.Fatalf("Expected an error when assigning to a user outside the project")
+	}
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if synced, _ := e.Task(task.ID); synced.AssigneeID != ID(alice.ID) || synced.AssignerID != ID(todoisttest.UserID) {
+		t.Fatalf("Expected the synced task assigned to Alice, got %+v", synced)
+	}
+
+	var nobody ID
+	if err := c.UpdateTaskWithParams(task.ID, &UpdateTaskParams{AssigneeID: &nobody}); err != nil {
+		t.Fatalf("Failed to unassign the task: %s", err)
+	}
+	if task, err = c.GetTask(task.ID); err != nil || task.AssigneeID != "" || task.AssignerID != "" {
+		t.Fatalf("Expected the task unassigned, got %v, %+v", err, task)
+	}
+}
+
+func TestUnifiedCollaborators(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	project := s.AddProject("Work")
+	task := s.AddTask(todoisttest.Task{Content: "shared", ProjectID: project.ID})
+
+	alice := s.AddCollaborator(project.ID, "Alice", "alice@example.com")
+	if collaborators, err := c.ListCollaborators(ID(project.ID)); err != nil || len(collaborators) != 2 {
+		t.Fatalf("Failed to list collaborators: %v, %+v", err, collaborators)
+	}
+	assigneeID := ID(alice.ID)
+	if err := c.UpdateTaskWithParams(ID(task.ID), &UpdateTaskParams{AssigneeID: &assigneeID}); err != nil {
+		t.Fatalf("Failed to assign the task: %s", err)
+	}
+	if assigned, err := c.GetTask(ID(task.ID)); err != nil || assigned.AssigneeID != assigneeID {
+		t.Fatalf("Expected the task assigned to Alice, got %v, %+v", err, assigned)
+	}
+}
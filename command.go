@@ -0,0 +1,259 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Command is a single Sync API command queued in a CommandBatch.
+type Command struct {
+	Type   string      `json:"type"`
+	Args   interface{} `json:"args"`
+	UUID   string      `json:"uuid"`
+	TempID string      `json:"temp_id,omitempty"`
+}
+
+// CommandError is the failure of a single command in a batch.
+type CommandError struct {
+	Command *Command
+	Code    int    `json:"error_code"`
+	Message string `json:"error"`
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("%s failed: %s (error_code %d)", e.Command.Type, e.Message, e.Code)
+}
+
+// BatchError is returned by Flush when some commands of a batch failed. The
+// other commands were applied.
+type BatchError struct {
+	Errors []*CommandError
+}
+
+func (e *BatchError) Error() string {
+	messages := []string{}
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// CommandResult is the outcome of a flushed batch.
+type CommandResult struct {
+	// TempIDMapping maps the temp IDs of created objects to their real IDs.
+	TempIDMapping map[string]ID
+	// Status holds the error of every failed command keyed by UUID.
+	// Commands that succeeded have no entry.
+	Status map[string]*CommandError
+}
+
+// ID returns the real ID assigned to an object created with tempID.
+func (r *CommandResult) ID(tempID string) (ID, bool) {
+	id, ok := r.TempIDMapping[tempID]
+	return id, ok
+}
+
+// Err returns the error of the command, or nil if it succeeded.
+func (r *CommandResult) Err(c *Command) error {
+	if err, ok := r.Status[c.UUID]; ok {
+		return err
+	}
+	return nil
+}
+
+// CommandBatch queues Sync API commands and sends them in a single request.
+// Objects created by the batch can be referenced by later commands through
+// their temp ID.
+type CommandBatch struct {
+	client   *Client
+	commands []*Command
+}
+
+func (c *Client) NewCommandBatch() *CommandBatch {
+	return &CommandBatch{client: c}
+}
+
+// Len returns the number of queued commands.
+func (b *CommandBatch) Len() int {
+	return len(b.commands)
+}
+
+// ID returns the temp ID of a command creating an object, usable as the
+// ID of that object in later commands of the same batch.
+func (c *Command) ID() ID {
+	return ID(c.TempID)
+}
+
+// Add queues a command of an arbitrary type.
+func (b *CommandBatch) Add(typeString string, args interface{}) *Command {
+	c := &Command{
+		Type: typeString,
+		Args: args,
+		UUID: uuid.New().String(),
+	}
+	b.commands = append(b.commands, c)
+	return c
+}
+
+// addObject queues a command creating an object, which gets a temp ID.
+func (b *CommandBatch) addObject(typeString string, args map[string]interface{}) *Command {
+	c := b.Add(typeString, args)
+	c.TempID = uuid.New().String()
+	return c
+}
+
+func mergeArgs(args map[string]interface{}, extra map[string]interface{}) map[string]interface{} {
+	for k, v := range extra {
+		args[k] = v
+	}
+	return args
+}
+
+func (b *CommandBatch) ItemAdd(content string, args map[string]interface{}) *Command {
+	return b.addObject("item_add", mergeArgs(map[string]interface{}{"content": content}, args))
+}
+
+func (b *CommandBatch) ItemUpdate(id ID, args map[string]interface{}) *Command {
+	return b.Add("item_update", mergeArgs(map[string]interface{}{"id": id}, args))
+}
+
+func (b *CommandBatch) ItemMove(id ID, args map[string]interface{}) *Command {
+	return b.Add("item_move", mergeArgs(map[string]interface{}{"id": id}, args))
+}
+
+func (b *CommandBatch) ItemClose(id ID) *Command {
+	return b.Add("item_close", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) ItemUncomplete(id ID) *Command {
+	return b.Add("item_uncomplete", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) ItemDelete(id ID) *Command {
+	return b.Add("item_delete", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) ProjectAdd(name string, args map[string]interface{}) *Command {
+	return b.addObject("project_add", mergeArgs(map[string]interface{}{"name": name}, args))
+}
+
+func (b *CommandBatch) ProjectUpdate(id ID, args map[string]interface{}) *Command {
+	return b.Add("project_update", mergeArgs(map[string]interface{}{"id": id}, args))
+}
+
+func (b *CommandBatch) ProjectDelete(id ID) *Command {
+	return b.Add("project_delete", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) ProjectArchive(id ID) *Command {
+	return b.Add("project_archive", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) ProjectUnarchive(id ID) *Command {
+	return b.Add("project_unarchive", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) LabelAdd(name string, args map[string]interface{}) *Command {
+	return b.addObject("label_add", mergeArgs(map[string]interface{}{"name": name}, args))
+}
+
+func (b *CommandBatch) LabelUpdate(id ID, args map[string]interface{}) *Command {
+	return b.Add("label_update", mergeArgs(map[string]interface{}{"id": id}, args))
+}
+
+func (b *CommandBatch) LabelDelete(id ID) *Command {
+	return b.Add("label_delete", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) FilterAdd(name, query string, args map[string]interface{}) *Command {
+	return b.addObject("filter_add", mergeArgs(map[string]interface{}{"name": name, "query": query}, args))
+}
+
+func (b *CommandBatch) FilterUpdate(id ID, args map[string]interface{}) *Command {
+	return b.Add("filter_update", mergeArgs(map[string]interface{}{"id": id}, args))
+}
+
+func (b *CommandBatch) FilterDelete(id ID) *Command {
+	return b.Add("filter_delete", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) ReminderAdd(taskID ID, args map[string]interface{}) *Command {
+	return b.addObject("reminder_add", mergeArgs(map[string]interface{}{"item_id": taskID}, args))
+}
+
+func (b *CommandBatch) ReminderUpdate(id ID, args map[string]interface{}) *Command {
+	return b.Add("reminder_update", mergeArgs(map[string]interface{}{"id": id}, args))
+}
+
+func (b *CommandBatch) ReminderDelete(id ID) *Command {
+	return b.Add("reminder_delete", map[string]interface{}{"id": id})
+}
+
+func (b *CommandBatch) Flush() (*CommandResult, error) {
+	return b.FlushContext(context.Background())
+}
+
+// FlushContext sends the queued commands and empties the batch. The batch
+// keeps its commands when the request fails, so it can be flushed again. The
+// returned error is a *BatchError when the request succeeded but some
+// commands failed or were not acknowledged.
+func (b *CommandBatch) FlushContext(ctx context.Context) (*CommandResult, error) {
+	commands := b.commands
+
+	result := &CommandResult{
+		TempIDMapping: map[string]ID{},
+		Status:        map[string]*CommandError{},
+	}
+	if len(commands) == 0 {
+		return result, nil
+	}
+
+	data, err := json.Marshal(commands)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("commands", string(data))
+
+	var out struct {
+		SyncStatus    map[string]json.RawMessage `json:"sync_status"`
+		TempIDMapping map[string]ID              `json:"temp_id_mapping"`
+	}
+	if err := b.client.sync(ctx, params, &out); err != nil {
+		return nil, err
+	}
+	b.commands = nil
+
+	for k, v := range out.TempIDMapping {
+		result.TempIDMapping[k] = v
+	}
+
+	batchErr := &BatchError{}
+	for _, c := range commands {
+		status, ok := out.SyncStatus[c.UUID]
+		if ok && string(status) == `"ok"` {
+			continue
+		}
+
+		cmdErr := &CommandError{Command: c, Message: "no sync status"}
+		if ok {
+			if err := json.Unmarshal(status, cmdErr); err != nil || cmdErr.Message == "" {
+				cmdErr.Message = string(status)
+			}
+		}
+
+		result.Status[c.UUID] = cmdErr
+		batchErr.Errors = append(batchErr.Errors, cmdErr)
+	}
+
+	if len(batchErr.Errors) > 0 {
+		return result, batchErr
+	}
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package todoist
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestCommandBatch(t *testing.T) {
+	c, s := newTestClient(t)
+
+	b := c.NewCommandBatch()
+	project := b.ProjectAdd("Work", nil)
+	label := b.LabelAdd("urgent", nil)
+	item := b.ItemAdd("batched", map[string]interface{}{
+		"project_id": project.ID(),
+		"labels":     []ID{label.ID()},
+	})
+	missing := b.ItemClose("1")
+
+	result, err := b.Flush()
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Errors) != 1 {
+		t.Fatalf("Expected one failed command, got %v", err)
+	}
+	if result.Err(missing) == nil {
+		t.Fatal("Expected the command on a missing item to fail")
+	}
+	if result.Err(item) != nil {
+		t.Fatalf("Expected item_add to succeed: %s", result.Err(item))
+	}
+	if b.Len() != 0 {
+		t.Fatalf("Expected the batch to be emptied, got %d commands", b.Len())
+	}
+
+	projectID, ok := result.ID(project.TempID)
+	if !ok {
+		t.Fatal("Expected a temp ID mapping for the project")
+	}
+	itemID, ok := result.ID(item.TempID)
+	if !ok {
+		t.Fatal("Expected a temp ID mapping for the item")
+	}
+
+	created, _ := s.Task(todoisttest.ID(itemID))
+	if ID(created.ProjectID) != projectID || len(created.LabelIDs) != 1 {
+		t.Fatalf("Expected temp IDs to be resolved, got %+v", created)
+	}
+}
+
+func TestMoveTaskFailure(t *testing.T) {
+	c, s := newTestClient(t)
+	item := s.AddTask(todoisttest.Task{Content: "stay"})
+
+	err := c.MoveTask(ID(item.ID), &map[string]interface{}{"project_id": 1})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected the failed move to be reported, got %v", err)
+	}
+}
+
+func TestCommandBatchFailure(t *testing.T) {
+	c, s := newTestClient(t)
+
+	b := c.NewCommandBatch()
+	item := b.ItemAdd("kept", nil)
+
+	s.InjectFailure(todoisttest.Failure{Method: "POST", StatusCode: 400, Times: 1})
+	if _, err := b.Flush(); err == nil {
+		t.Fatal("Expected the failed request to be reported")
+	}
+	if b.Len() != 1 {
+		t.Fatalf("Expected the batch to keep its commands, got %d", b.Len())
+	}
+
+	s.Hook = func(w http.ResponseWriter, r *http.Request) bool {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"sync_status": {}, "temp_id_mapping": {}}`))
+		return true
+	}
+	result, err := b.Flush()
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || result.Err(item) == nil {
+		t.Fatalf("Expected an unacknowledged command to fail, got %v", err)
+	}
+	s.Hook = nil
+
+	b.ItemAdd("kept", nil)
+	if _, err := b.Flush(); err != nil || b.Len() != 0 {
+		t.Fatalf("Expected the batch to be flushed, got %v with %d commands", err, b.Len())
+	}
+}
@@ -1,28 +1,153 @@
 package todoist
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+
+	"github.com/google/uuid"
 )
 
+// Comment is a comment on either a task or a project.
 type Comment struct {
-	ID        uint   `json:"id"`
-	Posted    string `json:"posted"`
-	CommentID uint   `json:"comment_id"`
-	ProjectID uint   `json:"project_id"`
+	ID     ID     `json:"id"`
+	Posted string `json:"posted"`
+	// PosterID is only reported by the unified API. The synced notes carry
+	// it on REST v1.
+	PosterID  ID     `json:"posted_uid,omitempty"`
+	TaskID    ID     `json:"task_id,omitempty"`
+	ProjectID ID     `json:"project_id,omitempty"`
 	Content   string `json:"content"`
+
+	Attachment *Attachment `json:"attachment,omitempty"`
+}
+
+// UnmarshalJSON accepts comments of both API generations.
+func (c *Comment) UnmarshalJSON(data []byte) error {
+	type comment Comment
+	aux := struct {
+		*comment
+		PostedAt       *string     `json:"posted_at"`
+		ItemID         *ID         `json:"item_id"`
+		FileAttachment *Attachment `json:"file_attachment"`
+	}{comment: (*comment)(c)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.PostedAt != nil {
+		c.Posted = *aux.PostedAt
+	}
+	if aux.ItemID != nil {
+		c.TaskID = *aux.ItemID
+	}
+	if aux.FileAttachment != nil {
+		c.Attachment = aux.FileAttachment
+	}
+	return nil
 }
 
 func (c *Client) ListComments(args *map[string]interface{}) ([]*Comment, error) {
+	return c.ListCommentsContext(context.Background(), args)
+}
+
+func (c *Client) ListCommentsContext(ctx context.Context, args *map[string]interface{}) ([]*Comment, error) {
 	ro := NewRequestOption()
-	for k, v := range *args {
-		ro.Params[k] = fmt.Sprint(v)
+	if args != nil {
+		for k, v := range *args {
+			ro.Params[k] = fmt.Sprint(v)
+		}
 	}
 
-	resp, err := c.httpRequest("GET", restEndpoint("comments"), ro)
+	out := []*Comment{}
+	return out, c.list(ctx, c.restEndpoint("comments"), ro, &out)
+}
+
+func (c *Client) ListCommentsWithFilter(filter *CommentFilter) ([]*Comment, error) {
+	return c.ListCommentsWithFilterContext(context.Background(), filter)
+}
+
+func (c *Client) ListCommentsWithFilterContext(ctx context.Context, filter *CommentFilter) ([]*Comment, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := filter.args()
+	return c.ListCommentsContext(ctx, &args)
+}
+
+func (c *Client) AddComment(params *AddCommentParams) (*Comment, error) {
+	return c.AddCommentContext(context.Background(), params)
+}
+
+func (c *Client) AddCommentContext(ctx context.Context, params *AddCommentParams) (*Comment, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(params.args())
 	if err != nil {
 		return nil, err
 	}
 
-	out := []*Comment{}
-	return out, decodeJSON(resp, &out)
+	ro := NewRequestOption()
+	ro.Body = bytes.NewBuffer(data)
+	ro.Headers["X-Request-Id"] = uuid.New().String()
+	ro.Headers["Content-Type"] = "application/json"
+
+	resp, err := c.httpRequest(ctx, "POST", c.restEndpoint("/comments"), ro)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Comment)
+	return out, decodeJSON(resp, out)
+}
+
+func (c *Client) GetComment(id ID) (*Comment, error) {
+	return c.GetCommentContext(context.Background(), id)
+}
+
+func (c *Client) GetCommentContext(ctx context.Context, id ID) (*Comment, error) {
+	resp, err := c.httpRequest(ctx, "GET", c.restEndpoint("/comments", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Comment)
+	return out, decodeJSON(resp, out)
+}
+
+func (c *Client) UpdateComment(id ID, content string) error {
+	return c.UpdateCommentContext(context.Background(), id, content)
+}
+
+func (c *Client) UpdateCommentContext(ctx context.Context, id ID, content string) error {
+	if content == "" {
+		return invalid("content", "is required")
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"content": content})
+	if err != nil {
+		return err
+	}
+
+	ro := NewRequestOption()
+	ro.Body = bytes.NewBuffer(data)
+	ro.Headers["X-Request-Id"] = uuid.New().String()
+	ro.Headers["Content-Type"] = "application/json"
+
+	_, err = c.httpRequest(ctx, "POST", c.restEndpoint("/comments", id), ro)
+	return err
+}
+
+func (c *Client) DeleteComment(id ID) error {
+	return c.DeleteCommentContext(context.Background(), id)
+}
+
+func (c *Client) DeleteCommentContext(ctx context.Context, id ID) error {
+	_, err := c.httpRequest(ctx, "DELETE", c.restEndpoint("/comments", id), nil)
+	return err
 }
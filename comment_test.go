@@ -0,0 +1,84 @@
+package todoist
+
+import (
+	"testing"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestComment(t *testing.T) {
+	c, s := newTestClient(t)
+	task := s.AddTask(todoisttest.Task{Content: "commented"})
+	project := s.AddProject("Work")
+
+	comment, err := c.AddComment(&AddCommentParams{TaskID: ID(task.ID), Content: "first"})
+	if err != nil {
+		t.Fatalf("Failed to comment on the task: %s", err)
+	}
+	if comment.TaskID != ID(task.ID) || comment.PosterID != "" {
+		t.Fatalf("Failed to comment on the task: got %+v", comment)
+	}
+
+	other := s.AddComment(todoisttest.Comment{TaskID: task.ID, Content: "theirs", PosterID: "2"})
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	comments, err := c.ListCommentsWithFilter(&CommentFilter{TaskID: ID(task.ID)})
+	if err != nil || len(comments) != 2 {
+		t.Fatalf("Failed to list comments: %v, %+v", err, comments)
+	}
+	own, known := ownComments(comments, e.Notes(ID(task.ID)), e.User().ID)
+	if len(own) != 1 || own[0].ID != comment.ID || !known {
+		t.Fatalf("Expected the poster to be found among the synced notes, got %+v", own)
+	}
+	if own, known = ownComments(comments, nil, e.User().ID); len(own) != 0 || known {
+		t.Fatalf("Expected unknown posters without notes, got %+v", own)
+	}
+	if err := c.DeleteComment(ID(other.ID)); err != nil {
+		t.Fatalf("Failed to delete the comment: %s", err)
+	}
+
+	if _, err := c.AddComment(&AddCommentParams{ProjectID: ID(project.ID), Content: "project note"}); err != nil {
+		t.Fatalf("Failed to comment on the project: %s", err)
+	}
+
+	if err := c.UpdateComment(comment.ID, "edited"); err != nil {
+		t.Fatalf("Failed to update the comment: %s", err)
+	}
+	if comment, err = c.GetComment(comment.ID); err != nil || comment.Content != "edited" {
+		t.Fatalf("Failed to update the comment: %v, %+v", err, comment)
+	}
+
+	list, err := c.ListCommentsWithFilter(&CommentFilter{ProjectID: ID(project.ID)})
+	if err != nil {
+		t.Fatalf("Failed to list comments: %s", err)
+	}
+	if len(list) != 1 || list[0].Content != "project note" {
+		t.Fatalf("Expected the project comment only, got %+v", list)
+	}
+
+	if err := c.DeleteComment(comment.ID); err != nil {
+		t.Fatalf("Failed to delete the comment: %s", err)
+	}
+	if v, _ := s.Task(task.ID); v.CommentCount != 0 {
+		t.Fatalf("Expected the comment count to drop, got %d", v.CommentCount)
+	}
+
+	if _, err := c.AddComment(&AddCommentParams{TaskID: ID(task.ID), ProjectID: ID(project.ID), Content: "both"}); err == nil {
+		t.Fatal("Expected a validation error for a comment on both a task and a project")
+	}
+}
+
+func TestUnifiedComment(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	task := s.AddTask(todoisttest.Task{Content: "commented"})
+
+	comment, err := c.AddComment(&AddCommentParams{TaskID: ID(task.ID), Content: "note"})
+	if err != nil {
+		t.Fatalf("Failed to comment on the task: %s", err)
+	}
+	if comment.TaskID != ID(task.ID) || comment.Posted == "" || comment.PosterID != ID(todoisttest.UserID) {
+		t.Fatalf("Failed to comment on the task: got %+v", comment)
+	}
+}
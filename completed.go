@@ -0,0 +1,117 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// CompletedTask is an entry of the history of completed tasks.
+type CompletedTask struct {
+	ID ID `json:"id"`
+	// TaskID is the ID of the task, to pass to ReopenTask.
+	TaskID        ID     `json:"task_id"`
+	Content       string `json:"content"`
+	ProjectID     ID     `json:"project_id"`
+	SectionID     ID     `json:"section_id,omitempty"`
+	CompletedDate string `json:"completed_date"`
+}
+
+// UnmarshalJSON accepts completed tasks of both API generations. The unified
+// API returns the tasks themselves, so their ID is the task ID.
+func (t *CompletedTask) UnmarshalJSON(data []byte) error {
+	type completedTask CompletedTask
+	aux := struct {
+		*completedTask
+		CompletedAt *string `json:"completed_at"`
+	}{completedTask: (*completedTask)(t)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.CompletedAt != nil {
+		t.CompletedDate = *aux.CompletedAt
+	}
+	if t.TaskID == "" {
+		t.TaskID = t.ID
+	}
+	if t.SectionID == "0" {
+		t.SectionID = ""
+	}
+	return nil
+}
+
+func (t *CompletedTask) CompletedTime() time.Time {
+	time, _ := time.Parse(time.RFC3339, t.CompletedDate)
+	return time
+}
+
+// ListCompletedTasks lists completed tasks, most recently completed first.
+func (c *Client) ListCompletedTasks(filter *CompletedFilter) ([]*CompletedTask, error) {
+	return c.ListCompletedTasksContext(context.Background(), filter)
+}
+
+func (c *Client) ListCompletedTasksContext(ctx context.Context, filter *CompletedFilter) ([]*CompletedTask, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	limit := 0
+	if filter != nil {
+		limit = filter.Limit
+	}
+
+	ro := NewRequestOption()
+	for k, v := range filter.args(c.apiVersion == UnifiedAPI) {
+		ro.Params[k] = fmt.Sprint(v)
+	}
+
+	u := c.syncEndpoint("/completed/get_all")
+	if c.apiVersion == UnifiedAPI {
+		u = c.restEndpoint("/tasks/completed/by_completion_date")
+	}
+
+	out := []*CompletedTask{}
+	for offset := 0; limit == 0 || len(out) < limit; {
+		size := pageLimit
+		if limit != 0 && limit-len(out) < size {
+			size = limit - len(out)
+		}
+		ro.Params["limit"] = fmt.Sprint(size)
+		if c.apiVersion != UnifiedAPI {
+			ro.Params["offset"] = fmt.Sprint(offset)
+		}
+
+		resp, err := c.httpRequest(ctx, "GET", u, ro)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Items      []*CompletedTask `json:"items"`
+			NextCursor string           `json:"next_cursor"`
+		}
+		if err := decodeJSON(resp, &page); err != nil {
+			return nil, err
+		}
+		out = append(out, page.Items...)
+		offset += len(page.Items)
+
+		// The legacy API pages by offset and the unified one by cursor.
+		if c.apiVersion == UnifiedAPI {
+			if page.NextCursor == "" {
+				break
+			}
+			ro.Params["cursor"] = page.NextCursor
+		} else if len(page.Items) < size {
+			break
+		}
+	}
+
+	if limit != 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
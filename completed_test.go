@@ -0,0 +1,73 @@
+package todoist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestListCompletedTasks(t *testing.T) {
+	c, s := newTestClient(t)
+	project := s.AddProject("Work")
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	for i, content := range []string{"oldest", "older", "newer", "newest"} {
+		task := s.AddTask(todoisttest.Task{Content: content, ProjectID: project.ID})
+		s.CompleteTask(task.ID, now.Add(time.Duration(i-4)*24*time.Hour))
+	}
+	inbox := s.AddTask(todoisttest.Task{Content: "inbox"})
+	s.CompleteTask(inbox.ID, now.Add(-time.Hour))
+	s.AddTask(todoisttest.Task{Content: "active", ProjectID: project.ID})
+
+	list, err := c.ListCompletedTasks(nil)
+	if err != nil {
+		t.Fatalf("Failed to list completed tasks: %s", err)
+	}
+	if len(list) != 5 || list[0].Content != "inbox" || list[4].Content != "oldest" {
+		t.Fatalf("Expected 5 completed tasks, most recent first, got %+v", list)
+	}
+	if list[0].TaskID != ID(inbox.ID) || !list[0].CompletedTime().Equal(now.Add(-time.Hour)) {
+		t.Fatalf("Unexpected completed task %+v", list[0])
+	}
+
+	list, err = c.ListCompletedTasks(&CompletedFilter{
+		ProjectID: ID(project.ID),
+		Since:     now.Add(-3*24*time.Hour - time.Minute),
+		Until:     now.Add(-2*24*time.Hour + time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("Failed to list completed tasks: %s", err)
+	}
+	if len(list) != 2 || list[0].Content != "newer" || list[1].Content != "older" {
+		t.Fatalf("Expected the tasks completed in the range, got %+v", list)
+	}
+
+	if list, err = c.ListCompletedTasks(&CompletedFilter{Limit: 2}); err != nil || len(list) != 2 {
+		t.Fatalf("Expected 2 completed tasks, got %v, %+v", err, list)
+	}
+
+	if err := c.ReopenTask(list[1].TaskID); err != nil {
+		t.Fatalf("Failed to reopen the task: %s", err)
+	}
+	if list, err = c.ListCompletedTasks(nil); err != nil || len(list) != 4 {
+		t.Fatalf("Expected 4 completed tasks, got %v, %+v", err, list)
+	}
+}
+
+func TestUnifiedCompleted(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	project := s.AddProject("Work")
+	for i := 0; i < 3; i++ {
+		task := s.AddTask(todoisttest.Task{Content: "done", ProjectID: project.ID})
+		s.CompleteTask(task.ID, time.Now().Add(-time.Hour))
+	}
+
+	completed, err := c.ListCompletedTasks(&CompletedFilter{ProjectID: ID(project.ID)})
+	if err != nil {
+		t.Fatalf("Failed to list completed tasks: %s", err)
+	}
+	if len(completed) != 3 || completed[0].TaskID == "" || completed[0].CompletedTime().IsZero() {
+		t.Fatalf("Expected 3 completed tasks across pages, got %+v", completed)
+	}
+}
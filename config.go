@@ -11,7 +11,14 @@ const configFile = "todoist.json"
 type Config struct {
 	Token  string `json:"token"`
 	Filter string `json:"filter,omitempty"`
-	Closed uint   `json:"closed,omitempty"`
+	Closed ID     `json:"closed,omitempty"`
+	// Columns are the columns of the task table, out of ID, DueDate, Pri,
+	// Project, Section, Assignee, Duration, Deadline, Created, Creator,
+	// Content and Description.
+	Columns []string `json:"columns,omitempty"`
+	// Timezone, such as "Asia/Tokyo", overrides the timezone of the Todoist
+	// account that due dates are shown in.
+	Timezone string `json:"timezone,omitempty"`
 }
 
 func NewConfig() (*Config, error) {
@@ -23,14 +30,19 @@ func NewConfig() (*Config, error) {
 	}
 
 	if config.Token == "" {
-		fmt.Printf("Input API Token: ")
-		fmt.Scan(&config.Token)
-		config.Save()
+		config.PromptToken()
 	}
 
 	return &config, nil
 }
 
+// PromptToken reads a new API token from the terminal and saves it.
+func (c *Config) PromptToken() {
+	fmt.Printf("Input API Token: ")
+	fmt.Scan(&c.Token)
+	c.Save()
+}
+
 func (c *Config) Save() {
 	store.Save(configFile, c)
 }
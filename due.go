@@ -0,0 +1,184 @@
+package todoist
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Due is when a task is planned. Todoist emits it in several forms:
+//
+//   - a date without time, "2019-05-01", due that day wherever the user is;
+//   - a floating date and time without offset, "2019-05-01T09:00:00", due at
+//     that wall clock time in the timezone of the user;
+//   - a fixed date and time in UTC, "2019-05-01T00:00:00Z", whose Timezone
+//     names the zone it was set in.
+//
+// REST v1 puts the time in Datetime, while the Sync API and the unified API
+// put it in Date.
+type Due struct {
+	Date      string `json:"date,omitempty"`
+	Datetime  string `json:"datetime,omitempty"`
+	Recurring bool   `json:"recurring,omitempty"`
+	String    string `json:"string,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+}
+
+// UnmarshalJSON accepts the unified API's is_recurring as well.
+func (d *Due) UnmarshalJSON(data []byte) error {
+	type due Due
+	aux := struct {
+		*due
+		IsRecurring *bool `json:"is_recurring"`
+	}{due: (*due)(d)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.IsRecurring != nil {
+		d.Recurring = *aux.IsRecurring
+	}
+	return nil
+}
+
+const dateLayout = "2006-01-02"
+
+// value returns the date and time of the due in whichever field holds it.
+func (d *Due) value() string {
+	if d.Datetime != "" {
+		return d.Datetime
+	}
+	return d.Date
+}
+
+func (d *Due) IsZero() bool {
+	return d.value() == ""
+}
+
+// HasTime reports whether the due has a time of day.
+func (d *Due) HasTime() bool {
+	return len(d.value()) > len(dateLayout)
+}
+
+// IsFloating reports whether the due has a time of day without offset,
+// which follows the timezone of the user.
+func (d *Due) IsFloating() bool {
+	if !d.HasTime() {
+		return false
+	}
+	_, err := time.Parse(time.RFC3339, d.value())
+	return err != nil
+}
+
+// In returns the due time in loc. A date without time is midnight in loc,
+// and a floating time is a wall clock time in loc, unless Timezone fixes it
+// to another zone. It returns the zero time if the due is empty or invalid.
+func (d *Due) In(loc *time.Location) time.Time {
+	v := d.value()
+	if !d.HasTime() {
+		t, _ := time.ParseInLocation(dateLayout, v, loc)
+		return t
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.In(loc)
+	}
+
+	zone := loc
+	if l := loadLocation(d.Timezone); l != nil {
+		zone = l
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, v, zone); err == nil {
+			return t.In(loc)
+		}
+	}
+	return time.Time{}
+}
+
+// day returns the date the task is due in loc.
+func (d *Due) day(loc *time.Location) string {
+	if !d.HasTime() {
+		return d.value()
+	}
+	if t := d.In(loc); !t.IsZero() {
+		return t.Format(dateLayout)
+	}
+	return ""
+}
+
+var locations sync.Map
+
+// loadLocation returns the named timezone, or nil if the name is empty or
+// unknown. Timezones are loaded once.
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// DueTime returns the due time in the local timezone.
+func (t *Task) DueTime() time.Time {
+	return t.DueTimeIn(time.Local)
+}
+
+// DueTimeIn returns the due time in loc, the timezone of the user.
+func (t *Task) DueTimeIn(loc *time.Location) time.Time {
+	return t.Due.In(loc)
+}
+
+func (t *Task) DueString() string {
+	return t.DueStringIn(time.Local)
+}
+
+// DueStringIn formats the due time in loc, e.g. "2019-05-01(Wed) 09:00".
+func (t *Task) DueStringIn(loc *time.Location) string {
+	due := t.DueTimeIn(loc)
+	switch {
+	case due.IsZero():
+		return ""
+	case t.Due.HasTime():
+		return due.Format("2006-01-02(Mon) 15:04")
+	default:
+		return due.Format("2006-01-02(Mon)")
+	}
+}
+
+func (t *Task) IsOverdue() bool {
+	return t.IsOverdueAt(time.Now())
+}
+
+// IsOverdueAt reports whether the task is overdue at now, in the timezone of
+// now. A task due on a date without time is overdue from the next day on.
+func (t *Task) IsOverdueAt(now time.Time) bool {
+	switch {
+	case t.Due.IsZero():
+		return false
+	case t.Due.HasTime():
+		due := t.DueTimeIn(now.Location())
+		return !due.IsZero() && due.Before(now)
+	default:
+		return t.Due.day(now.Location()) < now.Format(dateLayout)
+	}
+}
+
+func (t *Task) IsDuedate() bool {
+	return t.IsDuedateAt(time.Now())
+}
+
+// IsDuedateAt reports whether the task is due on the day of now, in the
+// timezone of now.
+func (t *Task) IsDuedateAt(now time.Time) bool {
+	return !t.Due.IsZero() && t.Due.day(now.Location()) == now.Format(dateLayout)
+}
@@ -0,0 +1,136 @@
+package todoist
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %s", name, err)
+	}
+	return loc
+}
+
+func TestDueIn(t *testing.T) {
+	tests := []struct {
+		name string
+		due  Due
+		zone string
+		// wall is the due time on the clock of zone and utc the instant,
+		// both empty for an invalid due.
+		wall string
+		utc  string
+	}{
+		{"date", Due{Date: "2021-06-02"}, "Asia/Tokyo", "2021-06-02 00:00", "2021-06-01T15:00:00Z"},
+		{"date in the west", Due{Date: "2021-06-02"}, "America/New_York", "2021-06-02 00:00", "2021-06-02T04:00:00Z"},
+		{"rest datetime", Due{Date: "2021-06-01", Datetime: "2021-06-01T23:00:00Z"}, "Asia/Tokyo", "2021-06-02 08:00", "2021-06-01T23:00:00Z"},
+		{"sync fixed time", Due{Date: "2021-06-01T23:00:00Z", Timezone: "Asia/Tokyo"}, "America/New_York", "2021-06-01 19:00", "2021-06-01T23:00:00Z"},
+		{"fractional seconds", Due{Date: "2021-06-01T23:00:00.000000Z"}, "Asia/Tokyo", "2021-06-02 08:00", "2021-06-01T23:00:00Z"},
+		{"offset", Due{Date: "2021-06-02T08:00:00+09:00"}, "UTC", "2021-06-01 23:00", "2021-06-01T23:00:00Z"},
+		{"floating", Due{Date: "2021-06-02T08:00:00"}, "Asia/Tokyo", "2021-06-02 08:00", "2021-06-01T23:00:00Z"},
+		{"floating in the west", Due{Date: "2021-06-02T08:00:00"}, "America/New_York", "2021-06-02 08:00", "2021-06-02T12:00:00Z"},
+		{"floating rest datetime", Due{Date: "2021-06-02", Datetime: "2021-06-02T08:00:00"}, "Europe/London", "2021-06-02 08:00", "2021-06-02T07:00:00Z"},
+		{"floating without seconds", Due{Date: "2021-06-02T08:00"}, "UTC", "2021-06-02 08:00", "2021-06-02T08:00:00Z"},
+		{"floating in a fixed zone", Due{Date: "2021-06-02T08:00:00", Timezone: "Asia/Tokyo"}, "UTC", "2021-06-01 23:00", "2021-06-01T23:00:00Z"},
+		{"floating in an unknown zone", Due{Date: "2021-06-02T08:00:00", Timezone: "Mars/Olympus"}, "UTC", "2021-06-02 08:00", "2021-06-02T08:00:00Z"},
+		{"before spring forward", Due{Date: "2021-03-14T01:30:00"}, "America/New_York", "2021-03-14 01:30", "2021-03-14T06:30:00Z"},
+		{"after spring forward", Due{Date: "2021-03-14T03:30:00"}, "America/New_York", "2021-03-14 03:30", "2021-03-14T07:30:00Z"},
+		{"before fall back", Due{Date: "2021-10-31T00:30:00Z"}, "Europe/London", "2021-10-31 01:30", "2021-10-31T00:30:00Z"},
+		{"after fall back", Due{Date: "2021-10-31T01:30:00Z"}, "Europe/London", "2021-10-31 01:30", "2021-10-31T01:30:00Z"},
+		{"date across fall back", Due{Date: "2021-11-07"}, "America/New_York", "2021-11-07 00:00", "2021-11-07T04:00:00Z"},
+		{"empty", Due{}, "UTC", "", ""},
+		{"invalid date", Due{Date: "tomorrow"}, "UTC", "", ""},
+		{"invalid datetime", Due{Datetime: "2021-06-02 08:00"}, "UTC", "", ""},
+	}
+
+	for _, tt := range tests {
+		loc := mustLoadLocation(t, tt.zone)
+		got := tt.due.In(loc)
+
+		if tt.utc == "" {
+			if !got.IsZero() {
+				t.Errorf("%s: expected the zero time, got %s", tt.name, got)
+			}
+			continue
+		}
+		if wall := got.Format("2006-01-02 15:04"); wall != tt.wall || got.Location() != loc {
+			t.Errorf("%s: got %s in %s, want %s in %s", tt.name, wall, got.Location(), tt.wall, tt.zone)
+		}
+		if utc := got.UTC().Format(time.RFC3339); utc != tt.utc {
+			t.Errorf("%s: got the instant %s, want %s", tt.name, utc, tt.utc)
+		}
+	}
+}
+
+func TestDueDay(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		due      Due
+		now      time.Time
+		overdue  bool
+		duedate  bool
+		wantText string
+	}{
+		{"tokyo morning before", Due{Datetime: "2021-06-01T23:00:00Z"}, time.Date(2021, 6, 2, 7, 0, 0, 0, tokyo), false, true, "2021-06-02(Wed) 08:00"},
+		{"tokyo morning after", Due{Datetime: "2021-06-01T23:00:00Z"}, time.Date(2021, 6, 2, 9, 0, 0, 0, tokyo), true, true, "2021-06-02(Wed) 08:00"},
+		{"tokyo the day before", Due{Datetime: "2021-06-01T23:00:00Z"}, time.Date(2021, 6, 1, 23, 0, 0, 0, tokyo), false, false, "2021-06-02(Wed) 08:00"},
+		{"date after tokyo midnight", Due{Date: "2021-06-02"}, time.Date(2021, 6, 2, 0, 30, 0, 0, tokyo), false, true, "2021-06-02(Wed)"},
+		{"date the next day", Due{Date: "2021-06-02"}, time.Date(2021, 6, 3, 0, 10, 0, 0, tokyo), true, false, "2021-06-02(Wed)"},
+		{"date late in new york", Due{Date: "2021-06-02"}, time.Date(2021, 6, 2, 23, 30, 0, 0, newYork), false, true, "2021-06-02(Wed)"},
+		{"floating before", Due{Date: "2021-06-02T08:00:00"}, time.Date(2021, 6, 2, 7, 59, 0, 0, newYork), false, true, "2021-06-02(Wed) 08:00"},
+		{"floating after", Due{Date: "2021-06-02T08:00:00"}, time.Date(2021, 6, 2, 8, 1, 0, 0, newYork), true, true, "2021-06-02(Wed) 08:00"},
+		{"floating after fall back", Due{Date: "2021-11-07T09:00:00"}, time.Date(2021, 11, 7, 8, 30, 0, 0, newYork), false, true, "2021-11-07(Sun) 09:00"},
+		{"fixed across spring forward", Due{Date: "2021-03-14T13:00:00Z"}, time.Date(2021, 3, 14, 8, 30, 0, 0, newYork), false, true, "2021-03-14(Sun) 09:00"},
+		{"date across fall back", Due{Date: "2021-11-07"}, time.Date(2021, 11, 7, 23, 30, 0, 0, newYork), false, true, "2021-11-07(Sun)"},
+		{"no due", Due{}, time.Date(2021, 6, 2, 0, 0, 0, 0, tokyo), false, false, ""},
+	}
+
+	for _, tt := range tests {
+		task := &Task{Due: tt.due}
+		if got := task.IsOverdueAt(tt.now); got != tt.overdue {
+			t.Errorf("%s: IsOverdueAt got %v, want %v", tt.name, got, tt.overdue)
+		}
+		if got := task.IsDuedateAt(tt.now); got != tt.duedate {
+			t.Errorf("%s: IsDuedateAt got %v, want %v", tt.name, got, tt.duedate)
+		}
+		if got := task.DueStringIn(tt.now.Location()); got != tt.wantText {
+			t.Errorf("%s: DueStringIn got %q, want %q", tt.name, got, tt.wantText)
+		}
+	}
+}
+
+func TestDueUnmarshal(t *testing.T) {
+	var due Due
+	if err := json.Unmarshal([]byte(`{"date":"2021-06-02T08:00:00","is_recurring":true,"string":"every day at 8am","timezone":null}`), &due); err != nil {
+		t.Fatalf("Failed to decode the due: %s", err)
+	}
+	if !due.Recurring || !due.HasTime() || !due.IsFloating() {
+		t.Fatalf("Unexpected due %+v", due)
+	}
+
+	fixed := Due{Date: "2021-06-01T23:00:00Z", Timezone: "Asia/Tokyo"}
+	if !fixed.HasTime() || fixed.IsFloating() {
+		t.Fatalf("Expected a fixed time, got %+v", fixed)
+	}
+}
+
+func TestUserTimezone(t *testing.T) {
+	c, s := newTestClient(t)
+	s.Timezone = "Asia/Tokyo"
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if tz := e.User().TZInfo.Timezone; tz != "Asia/Tokyo" || loadLocation(tz) == nil {
+		t.Fatalf("Expected the timezone of the user, got %q", tz)
+	}
+}
@@ -0,0 +1,82 @@
+package todoist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when the Todoist API answers with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Endpoint   string
+	RequestID  string
+
+	// Message is the error text parsed from the response body, or the raw
+	// body when it is not JSON.
+	Message string
+	// ErrorCode is the Sync API error code, if the body carried one.
+	ErrorCode int
+	Body      []byte
+}
+
+func newAPIError(method, endpoint, requestID string, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     method,
+		Endpoint:   endpoint,
+		RequestID:  requestID,
+		Message:    strings.TrimSpace(string(body)),
+		Body:       body,
+	}
+
+	var out struct {
+		Error     string `json:"error"`
+		ErrorCode int    `json:"error_code"`
+	}
+	if err := json.Unmarshal(body, &out); err == nil && out.Error != "" {
+		e.Message = out.Error
+		e.ErrorCode = out.ErrorCode
+	}
+
+	return e
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.Message)
+}
+
+func statusCode(err error) int {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsUnauthorized reports whether err was caused by a missing or invalid
+// API token.
+func IsUnauthorized(err error) bool {
+	return statusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err was caused by insufficient permissions.
+func IsForbidden(err error) bool {
+	return statusCode(err) == http.StatusForbidden
+}
+
+// IsNotFound reports whether err was caused by a missing resource, e.g. a
+// task deleted elsewhere.
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsRateLimited reports whether err was caused by exceeding the rate limit.
+func IsRateLimited(err error) bool {
+	return statusCode(err) == http.StatusTooManyRequests
+}
@@ -0,0 +1,96 @@
+package todoist
+
+import (
+	"context"
+	"net/url"
+	"sort"
+)
+
+// Filter is a saved filter query. Saved filters are only available through
+// the Sync API.
+type Filter struct {
+	ID       ID     `json:"id"`
+	Name     string `json:"name"`
+	Query    string `json:"query"`
+	Color    Color  `json:"color,omitempty"`
+	Order    uint   `json:"order"`
+	Favorite bool   `json:"favorite"`
+}
+
+// ListFilters lists the saved filters ordered by position.
+func (c *Client) ListFilters() ([]*Filter, error) {
+	return c.ListFiltersContext(context.Background())
+}
+
+func (c *Client) ListFiltersContext(ctx context.Context) ([]*Filter, error) {
+	params := url.Values{}
+	params.Add("sync_token", "*")
+	params.Add("resource_types", `["filters"]`)
+
+	var out struct {
+		Filters []*syncFilter `json:"filters"`
+	}
+	if err := c.sync(ctx, params, &out); err != nil {
+		return nil, err
+	}
+
+	list := []*Filter{}
+	for _, v := range out.Filters {
+		if !v.IsDeleted {
+			list = append(list, v.filter())
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Order < list[j].Order })
+	return list, nil
+}
+
+// AddFilter saves a filter query and returns its ID.
+func (c *Client) AddFilter(params *AddFilterParams) (ID, error) {
+	return c.AddFilterContext(context.Background(), params)
+}
+
+func (c *Client) AddFilterContext(ctx context.Context, params *AddFilterParams) (ID, error) {
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+
+	b := c.NewCommandBatch()
+	cmd := b.FilterAdd(params.Name, params.Query, params.args())
+
+	result, err := b.FlushContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.ID(cmd.TempID)
+	return id, nil
+}
+
+func (c *Client) UpdateFilter(id ID, params *UpdateFilterParams) error {
+	return c.UpdateFilterContext(context.Background(), id, params)
+}
+
+func (c *Client) UpdateFilterContext(ctx context.Context, id ID, params *UpdateFilterParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	b := c.NewCommandBatch()
+	b.FilterUpdate(id, params.args())
+
+	_, err := b.FlushContext(ctx)
+	return err
+}
+
+func (c *Client) DeleteFilter(id ID) error {
+	return c.DeleteFilterContext(context.Background(), id)
+}
+
+func (c *Client) DeleteFilterContext(ctx context.Context, id ID) error {
+	b := c.NewCommandBatch()
+	b.FilterDelete(id)
+
+	_, err := b.FlushContext(ctx)
+	return err
+}
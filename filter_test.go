@@ -0,0 +1,54 @@
+package todoist
+
+import (
+	"testing"
+)
+
+func TestFilters(t *testing.T) {
+	c, s := newTestClient(t)
+	seeded := s.AddFilter("Today", "today | overdue")
+
+	id, err := c.AddFilter(&AddFilterParams{Name: "Urgent", Query: "p1", Favorite: true})
+	if err != nil {
+		t.Fatalf("Failed to add a filter: %s", err)
+	}
+	if id == "" {
+		t.Fatalf("Expected the ID of the new filter")
+	}
+
+	filters, err := c.ListFilters()
+	if err != nil {
+		t.Fatalf("Failed to list filters: %s", err)
+	}
+	if len(filters) != 2 || filters[0].ID != ID(seeded.ID) || filters[1].ID != id {
+		t.Fatalf("Expected the seeded and the new filter in order, got %+v", filters)
+	}
+	if f := filters[1]; f.Name != "Urgent" || f.Query != "p1" || !f.Favorite {
+		t.Fatalf("Unexpected filter %+v", f)
+	}
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if filters = e.Filters(); len(filters) != 2 {
+		t.Fatalf("Expected 2 synced filters, got %+v", filters)
+	}
+
+	if err := c.UpdateFilter(id, &UpdateFilterParams{Query: "p1 & today"}); err != nil {
+		t.Fatalf("Failed to update the filter: %s", err)
+	}
+	if err := c.DeleteFilter(ID(seeded.ID)); err != nil {
+		t.Fatalf("Failed to delete the filter: %s", err)
+	}
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if filters = e.Filters(); len(filters) != 1 || filters[0].Query != "p1 & today" {
+		t.Fatalf("Expected the updated filter only, got %+v", filters)
+	}
+
+	if err := c.DeleteFilter(ID(seeded.ID)); err == nil {
+		t.Fatalf("Expected an error deleting a deleted filter")
+	}
+}
@@ -1,12 +1,20 @@
 module github.com/haccht/todoist
 
-go 1.12
+go 1.17
 
 require (
-	github.com/BurntSushi/toml v0.3.1 // indirect
 	github.com/gdamore/tcell v1.1.2
 	github.com/google/uuid v1.1.1
 	github.com/rivo/tview v0.0.0-20190515161233-bd836ef13b4b
 	github.com/tucnak/store v0.0.0-20170905113834-b02ecdcc6dfb
+)
+
+require (
+	github.com/BurntSushi/toml v0.3.1 // indirect
+	github.com/gdamore/encoding v1.0.0 // indirect
+	github.com/lucasb-eyer/go-colorful v1.0.2 // indirect
+	github.com/mattn/go-runewidth v0.0.4 // indirect
+	github.com/rivo/uniseg v0.0.0-20190513083848-b9f5b9457d44 // indirect
+	golang.org/x/text v0.3.0 // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
@@ -0,0 +1,62 @@
+package todoist
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// ID identifies a Todoist object. The v1 REST and v8 Sync APIs use numeric
+// IDs while the unified API uses opaque strings, so IDs are kept as strings
+// and decoded from either form.
+type ID string
+
+func (id ID) String() string {
+	return string(id)
+}
+
+// IsNumeric reports whether id is a numeric ID of the older API generation.
+func (id ID) IsNumeric() bool {
+	_, err := strconv.ParseUint(string(id), 10, 64)
+	return err == nil
+}
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*id = ""
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = ID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = ID(n.String())
+	return nil
+}
+
+// MarshalJSON encodes numeric IDs as JSON numbers, which the older endpoints
+// require, and every other ID as a string.
+func (id ID) MarshalJSON() ([]byte, error) {
+	if id.IsNumeric() {
+		return []byte(id), nil
+	}
+	return json.Marshal(string(id))
+}
+
+// less orders numeric IDs by value and other IDs lexically.
+func (id ID) less(other ID) bool {
+	if id.IsNumeric() && other.IsNumeric() && len(id) != len(other) {
+		return len(id) < len(other)
+	}
+	return id < other
+}
@@ -1,17 +1,152 @@
 package todoist
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
 type Label struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Order uint   `json:"order"`
+	ID       ID     `json:"id"`
+	Name     string `json:"name"`
+	Color    Color  `json:"color,omitempty"`
+	Order    uint   `json:"order"`
+	Favorite bool   `json:"favorite"`
+}
+
+// UnmarshalJSON accepts labels of both API generations.
+func (l *Label) UnmarshalJSON(data []byte) error {
+	type label Label
+	aux := struct {
+		*label
+		IsFavorite *flag `json:"is_favorite"`
+	}{label: (*label)(l)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.IsFavorite != nil {
+		l.Favorite = bool(*aux.IsFavorite)
+	}
+	return nil
 }
 
 func (c *Client) ListLabels() ([]*Label, error) {
-	resp, err := c.httpRequest("GET", restEndpoint("labels"), nil)
+	return c.ListLabelsContext(context.Background())
+}
+
+func (c *Client) ListLabelsContext(ctx context.Context) ([]*Label, error) {
+	out := []*Label{}
+	return out, c.list(ctx, c.restEndpoint("labels"), nil, &out)
+}
+
+func (c *Client) AddLabel(params *AddLabelParams) (*Label, error) {
+	return c.AddLabelContext(context.Background(), params)
+}
+
+func (c *Client) AddLabelContext(ctx context.Context, params *AddLabelParams) (*Label, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(c.favoriteArgs(params.args()))
 	if err != nil {
 		return nil, err
 	}
 
-	out := []*Label{}
-	return out, decodeJSON(resp, &out)
+	ro := NewRequestOption()
+	ro.Body = bytes.NewBuffer(data)
+	ro.Headers["X-Request-Id"] = uuid.New().String()
+	ro.Headers["Content-Type"] = "application/json"
+
+	resp, err := c.httpRequest(ctx, "POST", c.restEndpoint("/labels"), ro)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Label)
+	return out, decodeJSON(resp, out)
+}
+
+func (c *Client) GetLabel(id ID) (*Label, error) {
+	return c.GetLabelContext(context.Background(), id)
+}
+
+func (c *Client) GetLabelContext(ctx context.Context, id ID) (*Label, error) {
+	resp, err := c.httpRequest(ctx, "GET", c.restEndpoint("/labels", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Label)
+	return out, decodeJSON(resp, out)
+}
+
+func (c *Client) UpdateLabel(id ID, params *UpdateLabelParams) error {
+	return c.UpdateLabelContext(context.Background(), id, params)
+}
+
+func (c *Client) UpdateLabelContext(ctx context.Context, id ID, params *UpdateLabelParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c.favoriteArgs(params.args()))
+	if err != nil {
+		return err
+	}
+
+	ro := NewRequestOption()
+	ro.Body = bytes.NewBuffer(data)
+	ro.Headers["X-Request-Id"] = uuid.New().String()
+	ro.Headers["Content-Type"] = "application/json"
+
+	_, err = c.httpRequest(ctx, "POST", c.restEndpoint("/labels", id), ro)
+	return err
+}
+
+// DeleteLabel deletes a label and removes it from every task.
+func (c *Client) DeleteLabel(id ID) error {
+	return c.DeleteLabelContext(context.Background(), id)
+}
+
+func (c *Client) DeleteLabelContext(ctx context.Context, id ID) error {
+	_, err := c.httpRequest(ctx, "DELETE", c.restEndpoint("/labels", id), nil)
+	return err
+}
+
+// labelList decodes the labels of a task, given as IDs by the older API
+// generation and as names by the unified API.
+type labelList struct {
+	IDs   []ID
+	Names []string
+}
+
+func (l *labelList) UnmarshalJSON(data []byte) error {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	for _, v := range list {
+		if len(v) > 0 && v[0] == '"' {
+			var name string
+			if err := json.Unmarshal(v, &name); err != nil {
+				return err
+			}
+			l.Names = append(l.Names, name)
+			continue
+		}
+
+		var id ID
+		if err := json.Unmarshal(v, &id); err != nil {
+			return err
+		}
+		l.IDs = append(l.IDs, id)
+	}
+
+	return nil
 }
@@ -0,0 +1,46 @@
+package todoist
+
+import (
+	"testing"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestLabel(t *testing.T) {
+	c, s := newTestClient(t)
+
+	label, err := c.AddLabel(&AddLabelParams{Name: "urgent", Color: "30"})
+	if err != nil {
+		t.Fatalf("Failed to create a label: %s", err)
+	}
+	if label.Name != "urgent" || label.Color != "30" {
+		t.Fatalf("Failed to create a label: got %+v", label)
+	}
+
+	if err := c.UpdateLabel(label.ID, &UpdateLabelParams{Name: "important"}); err != nil {
+		t.Fatalf("Failed to update the label: %s", err)
+	}
+	if label, err = c.GetLabel(label.ID); err != nil || label.Name != "important" {
+		t.Fatalf("Failed to update the label: %v, %+v", err, label)
+	}
+
+	task := s.AddTask(todoisttest.Task{Content: "labelled", LabelIDs: []todoisttest.ID{todoisttest.ID(label.ID)}})
+	if err := c.DeleteLabel(label.ID); err != nil {
+		t.Fatalf("Failed to delete the label: %s", err)
+	}
+	if v, _ := s.Task(task.ID); len(v.LabelIDs) != 0 {
+		t.Fatalf("Expected the label to be removed from the task, got %v", v.LabelIDs)
+	}
+
+	list, err := c.ListLabels()
+	if err != nil {
+		t.Fatalf("Failed to list labels: %s", err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("Expected no labels, got %+v", list)
+	}
+
+	if _, err := c.AddLabel(&AddLabelParams{Name: "two words"}); err == nil {
+		t.Fatal("Expected a validation error for a label name with a space")
+	}
+}
@@ -0,0 +1,804 @@
+package todoist
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError reports an argument rejected before any request is sent.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Invalid %s: %s", e.Field, e.Message)
+}
+
+func invalid(field, format string, a ...interface{}) error {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, a...)}
+}
+
+// NoDueDate is the DueString that removes the due date of a task.
+const NoDueDate = "no date"
+
+// DueParams sets the due date of a task. At most one of DueString, DueDate
+// and DueDatetime may be given.
+type DueParams struct {
+	// DueString is a human-readable date such as "every monday", or
+	// NoDueDate.
+	DueString string
+	// DueLang is the language of DueString, e.g. "en".
+	DueLang string
+	// DueDate is a date in YYYY-MM-DD format.
+	DueDate string
+	// DueDatetime is a date and time in RFC 3339 format.
+	DueDatetime string
+}
+
+func (p *DueParams) validate() error {
+	n := 0
+	for _, v := range []string{p.DueString, p.DueDate, p.DueDatetime} {
+		if v != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		return invalid("due", "only one of due_string, due_date and due_datetime can be set")
+	}
+
+	if p.DueLang != "" && p.DueString == "" {
+		return invalid("due_lang", "requires due_string")
+	}
+	if p.DueDate != "" {
+		if _, err := time.Parse("2006-01-02", p.DueDate); err != nil {
+			return invalid("due_date", "%q is not in YYYY-MM-DD format", p.DueDate)
+		}
+	}
+	if p.DueDatetime != "" {
+		if _, err := time.Parse(time.RFC3339, p.DueDatetime); err != nil {
+			return invalid("due_datetime", "%q is not in RFC 3339 format", p.DueDatetime)
+		}
+	}
+	return nil
+}
+
+func (p *DueParams) args(args map[string]interface{}) {
+	setString(args, "due_string", p.DueString)
+	setString(args, "due_lang", p.DueLang)
+	setString(args, "due_date", p.DueDate)
+	setString(args, "due_datetime", p.DueDatetime)
+}
+
+func validatePriority(priority int) error {
+	if priority != 0 && (priority < 1 || 4 < priority) {
+		return invalid("priority", "%d is not between 1 and 4", priority)
+	}
+	return nil
+}
+
+// AddTaskParams are the arguments to create a task. Zero values are left out
+// of the request.
+type AddTaskParams struct {
+	Content     string
+	Description string
+	ProjectID   ID
+	SectionID   ID
+	// ParentID creates the task as a subtask.
+	ParentID ID
+	Order    uint
+	LabelIDs []ID
+	// Labels are label names, which the unified API takes instead of
+	// LabelIDs.
+	Labels []string
+	// Priority ranges from 1 (normal) to 4 (urgent).
+	Priority int
+	// AssigneeID assigns the task to a collaborator of its project.
+	AssigneeID ID
+	Duration   *Duration
+	// DeadlineDate is the deadline in YYYY-MM-DD format.
+	DeadlineDate string
+	DueParams
+}
+
+func (p *AddTaskParams) Validate() error {
+	if p == nil || p.Content == "" {
+		return invalid("content", "is required")
+	}
+	if err := validatePriority(p.Priority); err != nil {
+		return err
+	}
+	if err := validateDuration(p.Duration, false); err != nil {
+		return err
+	}
+	if err := validateDeadline(p.DeadlineDate); err != nil {
+		return err
+	}
+	return p.DueParams.validate()
+}
+
+func (p *AddTaskParams) args() map[string]interface{} {
+	args := map[string]interface{}{"content": p.Content}
+	setString(args, "description", p.Description)
+	setID(args, "project_id", p.ProjectID)
+	setID(args, "section_id", p.SectionID)
+	setID(args, "parent_id", p.ParentID)
+	setID(args, "assignee", p.AssigneeID)
+	if p.Order != 0 {
+		args["order"] = p.Order
+	}
+	if len(p.LabelIDs) > 0 {
+		args["label_ids"] = p.LabelIDs
+	}
+	if len(p.Labels) > 0 {
+		args["labels"] = p.Labels
+	}
+	if p.Priority != 0 {
+		args["priority"] = p.Priority
+	}
+	if p.Duration != nil {
+		args["duration"] = p.Duration.Amount
+		args["duration_unit"] = p.Duration.Unit
+	}
+	setString(args, "deadline_date", p.DeadlineDate)
+	p.DueParams.args(args)
+	return args
+}
+
+// UpdateTaskParams are the arguments to update a task. Zero values are left
+// unchanged, except LabelIDs and Labels where an empty non-nil slice removes
+// every label.
+type UpdateTaskParams struct {
+	Content string
+	// Description replaces the description, and an empty one removes it.
+	Description *string
+	LabelIDs    []ID
+	// Labels are label names, which the unified API takes instead of
+	// LabelIDs.
+	Labels []string
+	// Priority ranges from 1 (normal) to 4 (urgent).
+	Priority int
+	// AssigneeID assigns the task to a collaborator of its project, or
+	// unassigns it when it points to an empty ID.
+	AssigneeID *ID
+	// Duration replaces the duration, and a zero Amount removes it.
+	Duration *Duration
+	// DeadlineDate replaces the deadline, and an empty date removes it.
+	DeadlineDate *string
+	DueParams
+}
+
+func (p *UpdateTaskParams) Validate() error {
+	if p == nil || len(p.args()) == 0 {
+		return invalid("arguments", "nothing to update")
+	}
+	if err := validatePriority(p.Priority); err != nil {
+		return err
+	}
+	if err := validateDuration(p.Duration, true); err != nil {
+		return err
+	}
+	if p.DeadlineDate != nil {
+		if err := validateDeadline(*p.DeadlineDate); err != nil {
+			return err
+		}
+	}
+	return p.DueParams.validate()
+}
+
+func (p *UpdateTaskParams) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	setString(args, "content", p.Content)
+	if p.LabelIDs != nil {
+		args["label_ids"] = p.LabelIDs
+	}
+	if p.Labels != nil {
+		args["labels"] = p.Labels
+	}
+	if p.Priority != 0 {
+		args["priority"] = p.Priority
+	}
+	if p.AssigneeID != nil {
+		args["assignee"] = nil
+		setID(args, "assignee", *p.AssigneeID)
+	}
+	if p.Description != nil {
+		args["description"] = *p.Description
+	}
+	if p.Duration != nil {
+		args["duration"] = nil
+		if p.Duration.Amount != 0 {
+			args["duration"] = p.Duration.Amount
+			args["duration_unit"] = p.Duration.Unit
+		}
+	}
+	if p.DeadlineDate != nil {
+		args["deadline_date"] = nil
+		setString(args, "deadline_date", *p.DeadlineDate)
+	}
+	p.DueParams.args(args)
+	return args
+}
+
+// validateDuration accepts a nil duration and, if zero is true, a zero
+// amount that removes the duration.
+func validateDuration(d *Duration, zero bool) error {
+	switch {
+	case d == nil, zero && d.Amount == 0:
+		return nil
+	case d.Amount <= 0:
+		return invalid("duration", "%d is not positive", d.Amount)
+	case d.Unit != DurationMinute && d.Unit != DurationDay:
+		return invalid("duration_unit", "%q is not minute or day", d.Unit)
+	}
+	return nil
+}
+
+func validateDeadline(date string) error {
+	if date == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return invalid("deadline_date", "%q is not in YYYY-MM-DD format", date)
+	}
+	return nil
+}
+
+// MoveTaskParams is the destination of a moved task. Exactly one field must
+// be set. Moving to a project puts the task outside of any section.
+type MoveTaskParams struct {
+	ProjectID ID
+	SectionID ID
+	ParentID  ID
+}
+
+func (p *MoveTaskParams) Validate() error {
+	if p == nil || len(p.args()) != 1 {
+		return invalid("destination", "exactly one of project_id, section_id and parent_id must be set")
+	}
+	return nil
+}
+
+func (p *MoveTaskParams) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	setID(args, "project_id", p.ProjectID)
+	setID(args, "section_id", p.SectionID)
+	setID(args, "parent_id", p.ParentID)
+	return args
+}
+
+// QuickAddParams are the optional arguments of a quick add.
+type QuickAddParams struct {
+	// Note is added as a comment to the new task.
+	Note string
+	// Reminder is a date string for a reminder on the new task.
+	Reminder string
+	// AutoReminder adds the default reminder when the task has a due time.
+	AutoReminder bool
+}
+
+func (p *QuickAddParams) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	if p == nil {
+		return args
+	}
+
+	setString(args, "note", p.Note)
+	setString(args, "reminder", p.Reminder)
+	if p.AutoReminder {
+		args["auto_reminder"] = true
+	}
+	return args
+}
+
+// AddProjectParams are the arguments to create a project. Zero values are
+// left out of the request.
+type AddProjectParams struct {
+	Name string
+	// ParentID creates the project as a sub-project.
+	ParentID ID
+	Color    Color
+	Favorite bool
+}
+
+func (p *AddProjectParams) Validate() error {
+	if p == nil || p.Name == "" {
+		return invalid("name", "is required")
+	}
+	return nil
+}
+
+func (p *AddProjectParams) args() map[string]interface{} {
+	args := map[string]interface{}{"name": p.Name}
+	setID(args, "parent_id", p.ParentID)
+	setColor(args, p.Color)
+	if p.Favorite {
+		args["favorite"] = true
+	}
+	return args
+}
+
+// UpdateProjectParams are the arguments to update a project. Zero values are
+// left unchanged.
+type UpdateProjectParams struct {
+	Name     string
+	Color    Color
+	Favorite *bool
+}
+
+func (p *UpdateProjectParams) Validate() error {
+	if p == nil || len(p.args()) == 0 {
+		return invalid("arguments", "nothing to update")
+	}
+	return nil
+}
+
+func (p *UpdateProjectParams) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	setString(args, "name", p.Name)
+	setColor(args, p.Color)
+	if p.Favorite != nil {
+		args["favorite"] = *p.Favorite
+	}
+	return args
+}
+
+// AddLabelParams are the arguments to create a label. Zero values are left
+// out of the request.
+type AddLabelParams struct {
+	Name     string
+	Color    Color
+	Favorite bool
+}
+
+func (p *AddLabelParams) Validate() error {
+	if p == nil || p.Name == "" {
+		return invalid("name", "is required")
+	}
+	return validateLabelName(p.Name)
+}
+
+func (p *AddLabelParams) args() map[string]interface{} {
+	args := map[string]interface{}{"name": p.Name}
+	setColor(args, p.Color)
+	if p.Favorite {
+		args["favorite"] = true
+	}
+	return args
+}
+
+// UpdateLabelParams are the arguments to update a label. Zero values are left
+// unchanged.
+type UpdateLabelParams struct {
+	Name     string
+	Color    Color
+	Favorite *bool
+}
+
+func (p *UpdateLabelParams) Validate() error {
+	if p == nil || len(p.args()) == 0 {
+		return invalid("arguments", "nothing to update")
+	}
+	return validateLabelName(p.Name)
+}
+
+func (p *UpdateLabelParams) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	setString(args, "name", p.Name)
+	setColor(args, p.Color)
+	if p.Favorite != nil {
+		args["favorite"] = *p.Favorite
+	}
+	return args
+}
+
+// AddFilterParams are the arguments to save a filter. Zero values are left
+// out of the request.
+type AddFilterParams struct {
+	Name     string
+	Query    string
+	Color    Color
+	Favorite bool
+}
+
+func (p *AddFilterParams) Validate() error {
+	if p == nil || p.Name == "" {
+		return invalid("name", "is required")
+	}
+	if p.Query == "" {
+		return invalid("query", "is required")
+	}
+	return nil
+}
+
+// args returns the optional arguments; name and query are passed to
+// FilterAdd separately.
+func (p *AddFilterParams) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	setColor(args, p.Color)
+	if p.Favorite {
+		args["is_favorite"] = true
+	}
+	return args
+}
+
+// UpdateFilterParams are the arguments to update a saved filter. Zero values
+// are left unchanged.
+type UpdateFilterParams struct {
+	Name     string
+	Query    string
+	Color    Color
+	Favorite *bool
+}
+
+func (p *UpdateFilterParams) Validate() error {
+	if p == nil || len(p.args()) == 0 {
+		return invalid("arguments", "nothing to update")
+	}
+	return nil
+}
+
+func (p *UpdateFilterParams) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	setString(args, "name", p.Name)
+	setString(args, "query", p.Query)
+	setColor(args, p.Color)
+	if p.Favorite != nil {
+		args["is_favorite"] = *p.Favorite
+	}
+	return args
+}
+
+// validateLabelName rejects names that could not be written in a quick add
+// or a filter query.
+func validateLabelName(name string) error {
+	if strings.ContainsAny(name, " @") {
+		return invalid("name", "%q must not contain spaces or @", name)
+	}
+	return nil
+}
+
+// TaskFilter selects the active tasks to list. A nil or empty filter lists
+// every active task.
+type TaskFilter struct {
+	ProjectID ID
+	LabelID   ID
+	// Filter is a Todoist filter query such as "today | overdue". It cannot
+	// be combined with ProjectID or LabelID.
+	Filter string
+	// Lang is the language of Filter.
+	Lang string
+	IDs  []ID
+}
+
+func (f *TaskFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	if f.Filter != "" && (f.ProjectID != "" || f.LabelID != "") {
+		return invalid("filter", "cannot be combined with project_id or label_id")
+	}
+	if f.Lang != "" && f.Filter == "" {
+		return invalid("lang", "requires filter")
+	}
+	return nil
+}
+
+func (f *TaskFilter) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	if f == nil {
+		return args
+	}
+
+	setID(args, "project_id", f.ProjectID)
+	setID(args, "label_id", f.LabelID)
+	setString(args, "filter", f.Filter)
+	setString(args, "lang", f.Lang)
+	if len(f.IDs) > 0 {
+		ids := ""
+		for i, id := range f.IDs {
+			if i > 0 {
+				ids += ","
+			}
+			ids += fmt.Sprint(id)
+		}
+		args["ids"] = ids
+	}
+	return args
+}
+
+// CommentFilter selects the comments of either a task or a project.
+type CommentFilter struct {
+	TaskID    ID
+	ProjectID ID
+}
+
+func (f *CommentFilter) Validate() error {
+	if f == nil || (f.TaskID == "") == (f.ProjectID == "") {
+		return invalid("comment filter", "exactly one of task_id and project_id must be set")
+	}
+	return nil
+}
+
+func (f *CommentFilter) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	setID(args, "task_id", f.TaskID)
+	setID(args, "project_id", f.ProjectID)
+	return args
+}
+
+// CompletedFilter selects the completed tasks to list. A nil or empty filter
+// lists every completed task the API keeps.
+type CompletedFilter struct {
+	ProjectID ID
+	// Since and Until restrict the completion time. Zero values leave the
+	// range open, except that the unified API requires a range: there a zero
+	// Until means now and a zero Since four weeks before Until.
+	Since time.Time
+	Until time.Time
+	// Limit is the maximum number of tasks to return. Zero returns all.
+	Limit int
+}
+
+func (f *CompletedFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && f.Until.Before(f.Since) {
+		return invalid("until", "is before since")
+	}
+	if f.Limit < 0 {
+		return invalid("limit", "must not be negative")
+	}
+	return nil
+}
+
+func (f *CompletedFilter) args(unified bool) map[string]interface{} {
+	args := map[string]interface{}{}
+	if f == nil {
+		f = &CompletedFilter{}
+	}
+
+	setID(args, "project_id", f.ProjectID)
+
+	format := "2006-01-02T15:04"
+	since, until := f.Since, f.Until
+	if unified {
+		format = "2006-01-02T15:04:05Z"
+		if until.IsZero() {
+			until = time.Now()
+		}
+		if since.IsZero() {
+			since = until.AddDate(0, 0, -28)
+		}
+	}
+	if !since.IsZero() {
+		args["since"] = since.UTC().Format(format)
+	}
+	if !until.IsZero() {
+		args["until"] = until.UTC().Format(format)
+	}
+	return args
+}
+
+// ActivityFilter selects the events of the activity log to list. A nil or
+// empty filter lists every event.
+type ActivityFilter struct {
+	// ObjectType is one of ObjectTask, ObjectComment and ObjectProject.
+	ObjectType string
+	// ObjectID selects the events of one object and requires ObjectType.
+	ObjectID  ID
+	EventType string
+	// ObjectEventTypes selects events by "object:event" pairs, where either
+	// part may be empty, e.g. "item:completed" or "note:".
+	ObjectEventTypes []string
+	ParentProjectID  ID
+	ParentTaskID     ID
+	InitiatorID      ID
+	// Limit is the maximum number of events to return. Zero returns all.
+	Limit int
+}
+
+func (f *ActivityFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	switch f.ObjectType {
+	case "", ObjectTask, ObjectComment, ObjectProject:
+	default:
+		return invalid("object_type", "unknown type %s", f.ObjectType)
+	}
+	if f.ObjectID != "" && f.ObjectType == "" {
+		return invalid("object_id", "requires object_type")
+	}
+	switch f.EventType {
+	case "", EventAdded, EventUpdated, EventCompleted, EventUncompleted, EventDeleted,
+		EventArchived, EventUnarchived, EventShared, EventLeft:
+	default:
+		return invalid("event_type", "unknown type %s", f.EventType)
+	}
+	for _, v := range f.ObjectEventTypes {
+		if !strings.Contains(v, ":") {
+			return invalid("object_event_types", "%s is not of the form object:event", v)
+		}
+	}
+	if f.Limit < 0 {
+		return invalid("limit", "must not be negative")
+	}
+	return nil
+}
+
+func (f *ActivityFilter) args() map[string]interface{} {
+	args := map[string]interface{}{}
+	if f == nil {
+		return args
+	}
+
+	setString(args, "object_type", f.ObjectType)
+	setID(args, "object_id", f.ObjectID)
+	setString(args, "event_type", f.EventType)
+	if len(f.ObjectEventTypes) > 0 {
+		data, _ := json.Marshal(f.ObjectEventTypes)
+		args["object_event_types"] = string(data)
+	}
+	setID(args, "parent_project_id", f.ParentProjectID)
+	setID(args, "parent_item_id", f.ParentTaskID)
+	setID(args, "initiator_id", f.InitiatorID)
+	return args
+}
+
+// ReminderTime sets when a reminder fires. A relative reminder fires
+// MinuteOffset minutes before the due time of its task, an absolute one at
+// the time given by exactly one of DueString and DueDatetime.
+type ReminderTime struct {
+	// Type is ReminderRelative or ReminderAbsolute.
+	Type         string
+	MinuteOffset int
+	// DueString is a human-readable time such as "tomorrow at 9am".
+	DueString string
+	// DueDatetime is a date and time in RFC 3339 format.
+	DueDatetime string
+}
+
+func (p *ReminderTime) Validate() error {
+	if p == nil {
+		return invalid("type", "is required")
+	}
+
+	switch p.Type {
+	case ReminderRelative:
+		if p.MinuteOffset < 0 {
+			return invalid("minute_offset", "must not be negative")
+		}
+		if p.DueString != "" || p.DueDatetime != "" {
+			return invalid("due", "cannot be set on relative reminders")
+		}
+	case ReminderAbsolute:
+		if (p.DueString == "") == (p.DueDatetime == "") {
+			return invalid("due", "exactly one of due_string and due_datetime must be set")
+		}
+		if p.DueDatetime != "" {
+			if _, err := time.Parse(time.RFC3339, p.DueDatetime); err != nil {
+				return invalid("due_datetime", "%q is not in RFC 3339 format", p.DueDatetime)
+			}
+		}
+		if p.MinuteOffset != 0 {
+			return invalid("minute_offset", "cannot be set on absolute reminders")
+		}
+	default:
+		return invalid("type", "%q is neither relative nor absolute", p.Type)
+	}
+	return nil
+}
+
+func (p *ReminderTime) args(args map[string]interface{}) {
+	args["type"] = p.Type
+	switch {
+	case p.Type == ReminderRelative:
+		args["minute_offset"] = p.MinuteOffset
+	case p.DueString != "":
+		args["due"] = map[string]interface{}{"string": p.DueString}
+	default:
+		args["due"] = map[string]interface{}{"date": p.DueDatetime}
+	}
+}
+
+// AddReminderParams are the arguments to create a reminder.
+type AddReminderParams struct {
+	TaskID ID
+	ReminderTime
+}
+
+func (p *AddReminderParams) Validate() error {
+	if p == nil || p.TaskID == "" {
+		return invalid("item_id", "is required")
+	}
+	return p.ReminderTime.Validate()
+}
+
+// AddSectionParams are the arguments to create a section.
+type AddSectionParams struct {
+	Name      string
+	ProjectID ID
+	// Order is the position among the sections of the project. Zero appends
+	// the section.
+	Order uint
+}
+
+func (p *AddSectionParams) Validate() error {
+	if p == nil || p.Name == "" {
+		return invalid("name", "is required")
+	}
+	if p.ProjectID == "" {
+		return invalid("project_id", "is required")
+	}
+	return nil
+}
+
+func (p *AddSectionParams) args() map[string]interface{} {
+	args := map[string]interface{}{"name": p.Name, "project_id": p.ProjectID}
+	if p.Order != 0 {
+		args["order"] = p.Order
+	}
+	return args
+}
+
+// AddCommentParams are the arguments to comment on either a task or a
+// project.
+type AddCommentParams struct {
+	TaskID    ID
+	ProjectID ID
+	Content   string
+	// Attachment is a file uploaded with UploadFile.
+	Attachment *Attachment
+}
+
+func (p *AddCommentParams) Validate() error {
+	if p == nil || (p.TaskID == "") == (p.ProjectID == "") {
+		return invalid("comment target", "exactly one of task_id and project_id must be set")
+	}
+	if p.Content == "" {
+		return invalid("content", "is required")
+	}
+	if p.Attachment != nil && p.Attachment.FileURL == "" {
+		return invalid("attachment", "file_url is required")
+	}
+	return nil
+}
+
+func (p *AddCommentParams) args() map[string]interface{} {
+	args := map[string]interface{}{"content": p.Content}
+	setID(args, "task_id", p.TaskID)
+	setID(args, "project_id", p.ProjectID)
+	if p.Attachment != nil {
+		args["attachment"] = p.Attachment
+	}
+	return args
+}
+
+func setString(args map[string]interface{}, key, value string) {
+	if value != "" {
+		args[key] = value
+	}
+}
+
+// setColor sends numeric colors of the legacy API as numbers.
+func setColor(args map[string]interface{}, value Color) {
+	if value == "" {
+		return
+	}
+	if n, err := strconv.Atoi(string(value)); err == nil {
+		args["color"] = n
+	} else {
+		args["color"] = string(value)
+	}
+}
+
+func setID(args map[string]interface{}, key string, value ID) {
+	if value != "" {
+		args[key] = value
+	}
+}
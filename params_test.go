@@ -0,0 +1,103 @@
+package todoist
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParamsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		params interface{ Validate() error }
+		field  string
+	}{
+		{"add", &AddTaskParams{Content: "task", Priority: 4, DueParams: DueParams{DueString: "tomorrow", DueLang: "en"}}, ""},
+		{"add without content", &AddTaskParams{Priority: 1}, "content"},
+		{"add with priority 5", &AddTaskParams{Content: "task", Priority: 5}, "priority"},
+		{"add with two due fields", &AddTaskParams{Content: "task", DueParams: DueParams{DueString: "today", DueDate: "2019-05-01"}}, "due"},
+		{"add with invalid due date", &AddTaskParams{Content: "task", DueParams: DueParams{DueDate: "05/01/2019"}}, "due_date"},
+		{"add with due lang only", &AddTaskParams{Content: "task", DueParams: DueParams{DueLang: "en"}}, "due_lang"},
+		{"update", &UpdateTaskParams{LabelIDs: []ID{}}, ""},
+		{"update nothing", &UpdateTaskParams{}, "arguments"},
+		{"add with duration", &AddTaskParams{Content: "task", Duration: &Duration{Amount: 30, Unit: DurationMinute}, DeadlineDate: "2019-05-01"}, ""},
+		{"add with zero duration", &AddTaskParams{Content: "task", Duration: &Duration{Unit: DurationMinute}}, "duration"},
+		{"add with duration in hours", &AddTaskParams{Content: "task", Duration: &Duration{Amount: 1, Unit: "hour"}}, "duration_unit"},
+		{"add with invalid deadline", &AddTaskParams{Content: "task", DeadlineDate: "tomorrow"}, "deadline_date"},
+		{"update removing the duration", &UpdateTaskParams{Duration: &Duration{}}, ""},
+		{"update with invalid datetime", &UpdateTaskParams{DueParams: DueParams{DueDatetime: "2019-05-01 10:00"}}, "due_datetime"},
+		{"move", &MoveTaskParams{ProjectID: "1"}, ""},
+		{"move nowhere", &MoveTaskParams{}, "destination"},
+		{"move to two places", &MoveTaskParams{ProjectID: "1", ParentID: "2"}, "destination"},
+		{"task filter", &TaskFilter{Filter: "today", Lang: "en"}, ""},
+		{"task filter with project", &TaskFilter{Filter: "today", ProjectID: "1"}, "filter"},
+		{"comment filter", &CommentFilter{TaskID: "1"}, ""},
+		{"empty comment filter", &CommentFilter{}, "comment filter"},
+		{"completed filter", &CompletedFilter{Since: time.Unix(0, 0), Until: time.Unix(60, 0)}, ""},
+		{"completed filter range", &CompletedFilter{Since: time.Unix(60, 0), Until: time.Unix(0, 0)}, "until"},
+		{"completed filter limit", &CompletedFilter{Limit: -1}, "limit"},
+		{"relative reminder", &AddReminderParams{TaskID: "1", ReminderTime: ReminderTime{Type: ReminderRelative, MinuteOffset: 30}}, ""},
+		{"absolute reminder", &AddReminderParams{TaskID: "1", ReminderTime: ReminderTime{Type: ReminderAbsolute, DueString: "tomorrow"}}, ""},
+		{"reminder without task", &AddReminderParams{ReminderTime: ReminderTime{Type: ReminderRelative}}, "item_id"},
+		{"reminder type", &AddReminderParams{TaskID: "1", ReminderTime: ReminderTime{Type: "location"}}, "type"},
+		{"relative reminder due", &ReminderTime{Type: ReminderRelative, DueString: "tomorrow"}, "due"},
+		{"absolute reminder due", &ReminderTime{Type: ReminderAbsolute}, "due"},
+		{"absolute reminder datetime", &ReminderTime{Type: ReminderAbsolute, DueDatetime: "tomorrow"}, "due_datetime"},
+		{"filter", &AddFilterParams{Name: "Urgent", Query: "p1 & today"}, ""},
+		{"filter without query", &AddFilterParams{Name: "Urgent"}, "query"},
+		{"update filter nothing", &UpdateFilterParams{}, "arguments"},
+		{"activity filter", &ActivityFilter{ObjectType: ObjectTask, ObjectID: "1", EventType: EventCompleted}, ""},
+		{"activity object", &ActivityFilter{ObjectID: "1"}, "object_id"},
+		{"activity object type", &ActivityFilter{ObjectType: "task"}, "object_type"},
+		{"activity event type", &ActivityFilter{EventType: "moved"}, "event_type"},
+		{"activity object event types", &ActivityFilter{ObjectEventTypes: []string{"item"}}, "object_event_types"},
+	}
+
+	for _, tt := range tests {
+		err := tt.params.Validate()
+
+		var verr *ValidationError
+		switch {
+		case tt.field == "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		case tt.field != "" && !errors.As(err, &verr):
+			t.Errorf("%s: expected a validation error, got %v", tt.name, err)
+		case tt.field != "" && verr.Field != tt.field:
+			t.Errorf("%s: expected an error on %s, got %s", tt.name, tt.field, verr.Field)
+		}
+	}
+}
+
+func TestTaskWithParams(t *testing.T) {
+	c, s := newTestClient(t)
+	label := s.AddLabel("urgent")
+
+	item, err := c.AddTaskWithParams(&AddTaskParams{Content: "typed", Priority: 4, LabelIDs: []ID{ID(label.ID)}})
+	if err != nil {
+		t.Fatalf("Failed to create a task: %s", err)
+	}
+
+	err = c.UpdateTaskWithParams(item.ID, &UpdateTaskParams{LabelIDs: []ID{}, DueParams: DueParams{DueDate: "2019-05-01"}})
+	if err != nil {
+		t.Fatalf("Failed to update the task: %s", err)
+	}
+
+	list, err := c.ListTasksWithFilter(&TaskFilter{IDs: []ID{item.ID}})
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %s", err)
+	}
+	if len(list) != 1 || list[0].Priority != 4 || len(list[0].LabelIDs) != 0 || list[0].Due.Date != "2019-05-01" {
+		t.Fatalf("Unexpected tasks: %+v", list)
+	}
+
+	if _, err := c.AddTaskWithParams(&AddTaskParams{Content: "invalid", Priority: 9}); err == nil {
+		t.Fatal("Expected an invalid priority to be rejected")
+	}
+	if n := countRequests(s, "POST", "/rest/v1/tasks"); n != 1 {
+		t.Fatalf("Expected the invalid task not to be sent, got %d requests", n)
+	}
+
+	if _, err := c.ListComments(nil); err == nil {
+		t.Fatal("Expected listing comments without a filter to fail")
+	}
+}
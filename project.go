@@ -1,19 +1,234 @@
 package todoist
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
 type Project struct {
-	ID           uint   `json:"id"`
-	Name         string `json:"name"`
-	Order        uint   `json:"order"`
-	Indent       uint   `json:"indent"`
-	CommentCount uint   `json:"comment_count"`
+	ID       ID     `json:"id"`
+	ParentID ID     `json:"parent_id,omitempty"`
+	Name     string `json:"name"`
+	Color    Color  `json:"color,omitempty"`
+	Order    uint   `json:"order"`
+	// Indent is the nesting level reported by REST v1.
+	//
+	// Deprecated: the API no longer fills it in. Use ParentID or ProjectTree.
+	Indent       uint `json:"indent,omitempty"`
+	CommentCount uint `json:"comment_count"`
+	Favorite     bool `json:"favorite"`
+	InboxProject bool `json:"inbox_project"`
+	Archived     bool `json:"is_archived"`
+}
+
+// UnmarshalJSON accepts projects of both API generations.
+func (p *Project) UnmarshalJSON(data []byte) error {
+	type project Project
+	aux := struct {
+		*project
+		ChildOrder     *uint `json:"child_order"`
+		IsFavorite     *flag `json:"is_favorite"`
+		IsInboxProject *flag `json:"is_inbox_project"`
+	}{project: (*project)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.ChildOrder != nil {
+		p.Order = *aux.ChildOrder
+	}
+	if aux.IsFavorite != nil {
+		p.Favorite = bool(*aux.IsFavorite)
+	}
+	if aux.IsInboxProject != nil {
+		p.InboxProject = bool(*aux.IsInboxProject)
+	}
+	return nil
+}
+
+// Color is the color of a project or a label: a color name such as "berry_red"
+// in the unified API, a numeric color ID in the legacy one.
+type Color string
+
+func (c *Color) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = Color(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*c = Color(n)
+	return nil
+}
+
+// ProjectNode is a project together with its sub-projects.
+type ProjectNode struct {
+	*Project
+	Children []*ProjectNode
+}
+
+// Walk calls fn for the node and all its descendants in depth-first order.
+// The depth of the node itself is 0.
+func (n *ProjectNode) Walk(fn func(node *ProjectNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *ProjectNode) walk(fn func(node *ProjectNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// ProjectTree arranges projects by their ParentID and returns the top-level
+// projects. Siblings are sorted by Order, and projects whose parent is not in
+// the list are treated as top-level ones.
+func ProjectTree(projects []*Project) []*ProjectNode {
+	nodes := map[ID]*ProjectNode{}
+	for _, p := range projects {
+		nodes[p.ID] = &ProjectNode{Project: p}
+	}
+
+	roots := []*ProjectNode{}
+	for _, p := range projects {
+		node := nodes[p.ID]
+		if parent, ok := nodes[p.ParentID]; ok && p.ParentID != p.ID {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortProjectNodes(roots)
+	for _, node := range nodes {
+		sortProjectNodes(node.Children)
+	}
+	return roots
+}
+
+func sortProjectNodes(nodes []*ProjectNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Order != nodes[j].Order {
+			return nodes[i].Order < nodes[j].Order
+		}
+		return nodes[i].ID.less(nodes[j].ID)
+	})
 }
 
 func (c *Client) ListProjects() ([]*Project, error) {
-	resp, err := c.httpRequest("GET", restEndpoint("projects"), nil)
+	return c.ListProjectsContext(context.Background())
+}
+
+func (c *Client) ListProjectsContext(ctx context.Context) ([]*Project, error) {
+	out := []*Project{}
+	return out, c.list(ctx, c.restEndpoint("projects"), nil, &out)
+}
+
+func (c *Client) AddProject(params *AddProjectParams) (*Project, error) {
+	return c.AddProjectContext(context.Background(), params)
+}
+
+func (c *Client) AddProjectContext(ctx context.Context, params *AddProjectParams) (*Project, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(c.favoriteArgs(params.args()))
 	if err != nil {
 		return nil, err
 	}
 
-	out := []*Project{}
-	return out, decodeJSON(resp, &out)
+	ro := NewRequestOption()
+	ro.Body = bytes.NewBuffer(data)
+	ro.Headers["X-Request-Id"] = uuid.New().String()
+	ro.Headers["Content-Type"] = "application/json"
+
+	resp, err := c.httpRequest(ctx, "POST", c.restEndpoint("/projects"), ro)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Project)
+	return out, decodeJSON(resp, out)
+}
+
+func (c *Client) GetProject(id ID) (*Project, error) {
+	return c.GetProjectContext(context.Background(), id)
+}
+
+func (c *Client) GetProjectContext(ctx context.Context, id ID) (*Project, error) {
+	resp, err := c.httpRequest(ctx, "GET", c.restEndpoint("/projects", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Project)
+	return out, decodeJSON(resp, out)
+}
+
+func (c *Client) UpdateProject(id ID, params *UpdateProjectParams) error {
+	return c.UpdateProjectContext(context.Background(), id, params)
+}
+
+func (c *Client) UpdateProjectContext(ctx context.Context, id ID, params *UpdateProjectParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c.favoriteArgs(params.args()))
+	if err != nil {
+		return err
+	}
+
+	ro := NewRequestOption()
+	ro.Body = bytes.NewBuffer(data)
+	ro.Headers["X-Request-Id"] = uuid.New().String()
+	ro.Headers["Content-Type"] = "application/json"
+
+	_, err = c.httpRequest(ctx, "POST", c.restEndpoint("/projects", id), ro)
+	return err
+}
+
+// DeleteProject deletes a project together with its sub-projects and tasks.
+func (c *Client) DeleteProject(id ID) error {
+	return c.DeleteProjectContext(context.Background(), id)
+}
+
+func (c *Client) DeleteProjectContext(ctx context.Context, id ID) error {
+	_, err := c.httpRequest(ctx, "DELETE", c.restEndpoint("/projects", id), nil)
+	return err
+}
+
+// ArchiveProject archives a project together with its sub-projects.
+func (c *Client) ArchiveProject(id ID) error {
+	return c.ArchiveProjectContext(context.Background(), id)
+}
+
+func (c *Client) ArchiveProjectContext(ctx context.Context, id ID) error {
+	b := c.NewCommandBatch()
+	b.ProjectArchive(id)
+
+	_, err := b.FlushContext(ctx)
+	return err
+}
+
+func (c *Client) UnarchiveProject(id ID) error {
+	return c.UnarchiveProjectContext(context.Background(), id)
+}
+
+func (c *Client) UnarchiveProjectContext(ctx context.Context, id ID) error {
+	b := c.NewCommandBatch()
+	b.ProjectUnarchive(id)
+
+	_, err := b.FlushContext(ctx)
+	return err
 }
@@ -0,0 +1,115 @@
+package todoist
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestProject(t *testing.T) {
+	c, s := newTestClient(t)
+
+	parent, err := c.AddProject(&AddProjectParams{Name: "Work", Color: "31", Favorite: true})
+	if err != nil {
+		t.Fatalf("Failed to create a project: %s", err)
+	}
+	if parent.Color != "31" || !parent.Favorite {
+		t.Fatalf("Failed to create a project: got %+v", parent)
+	}
+
+	child, err := c.AddProject(&AddProjectParams{Name: "Reports", ParentID: parent.ID})
+	if err != nil {
+		t.Fatalf("Failed to create a sub-project: %s", err)
+	}
+	if child.ParentID != parent.ID {
+		t.Fatalf("Failed to create a sub-project: parent_id is %s, want %s", child.ParentID, parent.ID)
+	}
+
+	favorite := false
+	if err := c.UpdateProject(child.ID, &UpdateProjectParams{Name: "Weekly reports", Favorite: &favorite}); err != nil {
+		t.Fatalf("Failed to update the project: %s", err)
+	}
+	if child, err = c.GetProject(child.ID); err != nil || child.Name != "Weekly reports" {
+		t.Fatalf("Failed to update the project: %v, %+v", err, child)
+	}
+
+	if err := c.ArchiveProject(parent.ID); err != nil {
+		t.Fatalf("Failed to archive the project: %s", err)
+	}
+	if p, _ := s.Project(todoisttest.ID(child.ID)); !p.Archived {
+		t.Fatal("Expected sub-projects to be archived with their parent")
+	}
+
+	list, err := c.ListProjects()
+	if err != nil {
+		t.Fatalf("Failed to list projects: %s", err)
+	}
+	if len(list) != 1 || !list[0].InboxProject {
+		t.Fatalf("Expected only the Inbox to be listed, got %+v", list)
+	}
+
+	if err := c.UnarchiveProject(parent.ID); err != nil {
+		t.Fatalf("Failed to unarchive the project: %s", err)
+	}
+
+	task := s.AddTask(todoisttest.Task{Content: "report", ProjectID: todoisttest.ID(child.ID)})
+	if err := c.DeleteProject(parent.ID); err != nil {
+		t.Fatalf("Failed to delete the project: %s", err)
+	}
+	if _, ok := s.Task(task.ID); ok {
+		t.Fatal("Expected tasks of deleted sub-projects to be deleted")
+	}
+	if _, err := c.GetProject(child.ID); !IsNotFound(err) {
+		t.Fatalf("Expected the sub-project to be deleted, got %v", err)
+	}
+
+	if _, err := c.AddProject(&AddProjectParams{}); err == nil {
+		t.Fatal("Expected a validation error for a project without name")
+	}
+}
+
+func TestProjectTree(t *testing.T) {
+	projects := []*Project{
+		{ID: "1", Name: "Inbox", Order: 0},
+		{ID: "2", Name: "Work", Order: 2},
+		{ID: "3", Name: "Reports", ParentID: "2", Order: 2},
+		{ID: "4", Name: "Meetings", ParentID: "2", Order: 1},
+		{ID: "5", Name: "Weekly", ParentID: "3", Order: 1},
+		{ID: "6", Name: "Orphan", ParentID: "99", Order: 1},
+	}
+
+	got := []string{}
+	for _, root := range ProjectTree(projects) {
+		root.Walk(func(node *ProjectNode, depth int) {
+			got = append(got, fmt.Sprintf("%d:%s", depth, node.Name))
+		})
+	}
+
+	want := []string{"0:Inbox", "0:Orphan", "0:Work", "1:Meetings", "1:Reports", "2:Weekly"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Unexpected project tree %v, want %v", got, want)
+	}
+}
+
+func TestUnifiedProject(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	project := s.AddProject("Work")
+
+	sub, err := c.AddProject(&AddProjectParams{Name: "Reports", ParentID: ID(project.ID), Favorite: true})
+	if err != nil {
+		t.Fatalf("Failed to create a project: %s", err)
+	}
+	if sub.ParentID != ID(project.ID) || !sub.Favorite {
+		t.Fatalf("Failed to create a project: got %+v", sub)
+	}
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if tree := ProjectTree(e.Projects()); len(tree) != 2 || len(tree[1].Children) != 1 {
+		t.Fatalf("Expected the synced sub-project under its parent, got %+v", tree)
+	}
+}
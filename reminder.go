@@ -0,0 +1,117 @@
+package todoist
+
+import (
+	"context"
+	"time"
+)
+
+// Reminder types.
+const (
+	ReminderRelative = "relative"
+	ReminderAbsolute = "absolute"
+)
+
+type Reminder struct {
+	ID     ID     `json:"id"`
+	TaskID ID     `json:"item_id"`
+	Type   string `json:"type"`
+	// MinuteOffset is how long before the due time of the task a relative
+	// reminder fires.
+	MinuteOffset int `json:"minute_offset,omitempty"`
+	// Due is when an absolute reminder fires.
+	Due *Due `json:"due,omitempty"`
+}
+
+// String describes when the reminder fires, e.g. "30 minutes before", in
+// the local timezone.
+func (r *Reminder) String() string {
+	return r.StringIn(time.Local)
+}
+
+// StringIn describes when the reminder fires with the time of an absolute
+// reminder in loc.
+func (r *Reminder) StringIn(loc *time.Location) string {
+	if r.Type == ReminderRelative {
+		return formatOffset(r.MinuteOffset)
+	}
+	if r.Due == nil {
+		return ""
+	}
+
+	if t := r.Due.In(loc); r.Due.HasTime() && !t.IsZero() {
+		return t.Format("2006-01-02(Mon) 15:04")
+	}
+	if r.Due.String != "" {
+		return r.Due.String
+	}
+	return r.Due.Date
+}
+
+func formatOffset(minutes int) string {
+	switch {
+	case minutes == 0:
+		return "at the due time"
+	case minutes%(24*60) == 0:
+		return plural(minutes/(24*60), "day") + " before"
+	case minutes%60 == 0:
+		return plural(minutes/60, "hour") + " before"
+	default:
+		return plural(minutes, "minute") + " before"
+	}
+}
+
+// AddReminder creates a reminder and returns its ID.
+func (c *Client) AddReminder(params *AddReminderParams) (ID, error) {
+	return c.AddReminderContext(context.Background(), params)
+}
+
+func (c *Client) AddReminderContext(ctx context.Context, params *AddReminderParams) (ID, error) {
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+
+	args := map[string]interface{}{}
+	params.ReminderTime.args(args)
+
+	b := c.NewCommandBatch()
+	cmd := b.ReminderAdd(params.TaskID, args)
+
+	result, err := b.FlushContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.ID(cmd.TempID)
+	return id, nil
+}
+
+func (c *Client) UpdateReminder(id ID, params *ReminderTime) error {
+	return c.UpdateReminderContext(context.Background(), id, params)
+}
+
+func (c *Client) UpdateReminderContext(ctx context.Context, id ID, params *ReminderTime) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	args := map[string]interface{}{}
+	params.args(args)
+
+	b := c.NewCommandBatch()
+	b.ReminderUpdate(id, args)
+
+	_, err := b.FlushContext(ctx)
+	return err
+}
+
+func (c *Client) DeleteReminder(id ID) error {
+	return c.DeleteReminderContext(context.Background(), id)
+}
+
+func (c *Client) DeleteReminderContext(ctx context.Context, id ID) error {
+	b := c.NewCommandBatch()
+	b.ReminderDelete(id)
+
+	_, err := b.FlushContext(ctx)
+	return err
+}
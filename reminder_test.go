@@ -0,0 +1,94 @@
+package todoist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestReminder(t *testing.T) {
+	c, s := newTestClient(t)
+	task := s.AddTask(todoisttest.Task{Content: "dentist", Due: &todoisttest.Due{Date: "2030-01-02", Datetime: "2030-01-02T09:00:00Z"}})
+	undated := s.AddTask(todoisttest.Task{Content: "someday"})
+
+	relative, err := c.AddReminder(&AddReminderParams{TaskID: ID(task.ID), ReminderTime: ReminderTime{Type: ReminderRelative, MinuteOffset: 30}})
+	if err != nil {
+		t.Fatalf("Failed to add a relative reminder: %s", err)
+	}
+	absolute, err := c.AddReminder(&AddReminderParams{TaskID: ID(task.ID), ReminderTime: ReminderTime{Type: ReminderAbsolute, DueDatetime: "2030-01-01T18:00:00Z"}})
+	if err != nil {
+		t.Fatalf("Failed to add an absolute reminder: %s", err)
+	}
+	if relative == "" || absolute == "" || relative == absolute {
+		t.Fatalf("Expected the IDs of the new reminders, got %q and %q", relative, absolute)
+	}
+
+	if _, err := c.AddReminder(&AddReminderParams{TaskID: ID(undated.ID), ReminderTime: ReminderTime{Type: ReminderRelative}}); err == nil {
+		t.Fatalf("Expected an error for a relative reminder on a task without due date")
+	}
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	reminders := e.Reminders(ID(task.ID))
+	if len(reminders) != 2 || reminders[0].MinuteOffset != 30 || reminders[1].Due == nil {
+		t.Fatalf("Expected 2 synced reminders, got %+v", reminders)
+	}
+	if got := reminders[0].String(); got != "30 minutes before" {
+		t.Fatalf("Unexpected relative reminder %q", got)
+	}
+	want := time.Date(2030, 1, 1, 18, 0, 0, 0, time.UTC).Local().Format("2006-01-02(Mon) 15:04")
+	if got := reminders[1].String(); got != want {
+		t.Fatalf("Unexpected absolute reminder %q, want %q", got, want)
+	}
+
+	if err := c.UpdateReminder(relative, &ReminderTime{Type: ReminderRelative, MinuteOffset: 60}); err != nil {
+		t.Fatalf("Failed to update the reminder: %s", err)
+	}
+	if err := c.DeleteReminder(absolute); err != nil {
+		t.Fatalf("Failed to delete the reminder: %s", err)
+	}
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if reminders = e.Reminders(ID(task.ID)); len(reminders) != 1 || reminders[0].String() != "1 hour before" {
+		t.Fatalf("Expected the updated reminder only, got %+v", reminders)
+	}
+
+	if err := c.DeleteTask(ID(task.ID)); err != nil {
+		t.Fatalf("Failed to delete the task: %s", err)
+	}
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if reminders = e.Reminders(ID(task.ID)); len(reminders) != 0 {
+		t.Fatalf("Expected the reminders to be deleted with the task, got %+v", reminders)
+	}
+}
+
+func TestReminderString(t *testing.T) {
+	tests := []struct {
+		reminder *Reminder
+		want     string
+	}{
+		{&Reminder{Type: ReminderRelative}, "at the due time"},
+		{&Reminder{Type: ReminderRelative, MinuteOffset: 1}, "1 minute before"},
+		{&Reminder{Type: ReminderRelative, MinuteOffset: 90}, "90 minutes before"},
+		{&Reminder{Type: ReminderRelative, MinuteOffset: 120}, "2 hours before"},
+		{&Reminder{Type: ReminderRelative, MinuteOffset: 1440}, "1 day before"},
+		{&Reminder{Type: ReminderAbsolute, Due: &Due{String: "tomorrow at 9am"}}, "tomorrow at 9am"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.reminder.String(); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.reminder, got, tt.want)
+		}
+	}
+
+	absolute := &Reminder{Type: ReminderAbsolute, Due: &Due{Date: "2021-06-01T23:00:00Z"}}
+	if got := absolute.StringIn(mustLoadLocation(t, "Asia/Tokyo")); got != "2021-06-02(Wed) 08:00" {
+		t.Errorf("Expected the reminder time in Tokyo, got %q", got)
+	}
+}
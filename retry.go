@@ -0,0 +1,132 @@
+package todoist
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how Client retries requests that failed with a
+// transient error. Only idempotent requests are retried: safe HTTP methods
+// and requests carrying an X-Request-Id or Sync command UUIDs.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry. It doubles on every
+	// subsequent attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait between attempts. A Retry-After header asking
+	// for a longer wait stops retrying instead.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, by which the backoff is
+	// randomly spread to avoid synchronized retries.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is used by clients created with NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+	Jitter:      0.2,
+}
+
+// NoRetry disables retries.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy replaces the retry policy of the client.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
+
+// next reports whether the failed attempt should be retried and how long to
+// wait before doing so.
+func (p RetryPolicy) next(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if err == nil && !isRetryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+
+	wait := p.backoff(attempt)
+	if err == nil {
+		if d, ok := retryAfter(resp); ok {
+			if p.MaxBackoff > 0 && d > p.MaxBackoff {
+				return 0, false
+			}
+			wait = d
+		}
+	}
+
+	return wait, true
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+
+	if p.Jitter > 0 {
+		d += time.Duration(p.Jitter * float64(d) * (2*rand.Float64() - 1))
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	return d
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as
+// an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(method string, ro *RequestOption) bool {
+	switch method {
+	case "GET", "HEAD", "PUT", "DELETE", "OPTIONS":
+		return true
+	}
+
+	return ro.Idempotent || ro.Headers["X-Request-Id"] != ""
+}
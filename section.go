@@ -0,0 +1,126 @@
+package todoist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type Section struct {
+	ID        ID     `json:"id"`
+	Name      string `json:"name"`
+	ProjectID ID     `json:"project_id"`
+	Order     uint   `json:"order"`
+}
+
+// UnmarshalJSON accepts sections of both API generations.
+func (s *Section) UnmarshalJSON(data []byte) error {
+	type section Section
+	aux := struct {
+		*section
+		SectionOrder *uint `json:"section_order"`
+	}{section: (*section)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.SectionOrder != nil {
+		s.Order = *aux.SectionOrder
+	}
+	return nil
+}
+
+// ListSections lists the sections of a project, or of every project when
+// projectID is empty.
+func (c *Client) ListSections(projectID ID) ([]*Section, error) {
+	return c.ListSectionsContext(context.Background(), projectID)
+}
+
+func (c *Client) ListSectionsContext(ctx context.Context, projectID ID) ([]*Section, error) {
+	ro := NewRequestOption()
+	if projectID != "" {
+		ro.Params["project_id"] = projectID.String()
+	}
+
+	out := []*Section{}
+	return out, c.list(ctx, c.restEndpoint("sections"), ro, &out)
+}
+
+func (c *Client) AddSection(params *AddSectionParams) (*Section, error) {
+	return c.AddSectionContext(context.Background(), params)
+}
+
+func (c *Client) AddSectionContext(ctx context.Context, params *AddSectionParams) (*Section, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(params.args())
+	if err != nil {
+		return nil, err
+	}
+
+	ro := NewRequestOption()
+	ro.Body = bytes.NewBuffer(data)
+	ro.Headers["X-Request-Id"] = uuid.New().String()
+	ro.Headers["Content-Type"] = "application/json"
+
+	resp, err := c.httpRequest(ctx, "POST", c.restEndpoint("/sections"), ro)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Section)
+	return out, decodeJSON(resp, out)
+}
+
+func (c *Client) GetSection(id ID) (*Section, error) {
+	return c.GetSectionContext(context.Background(), id)
+}
+
+func (c *Client) GetSectionContext(ctx context.Context, id ID) (*Section, error) {
+	resp, err := c.httpRequest(ctx, "GET", c.restEndpoint("/sections", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Section)
+	return out, decodeJSON(resp, out)
+}
+
+// UpdateSection renames a section.
+func (c *Client) UpdateSection(id ID, name string) error {
+	return c.UpdateSectionContext(context.Background(), id, name)
+}
+
+func (c *Client) UpdateSectionContext(ctx context.Context, id ID, name string) error {
+	if name == "" {
+		return invalid("name", "is required")
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"name": name})
+	if err != nil {
+		return err
+	}
+
+	ro := NewRequestOption()
+	ro.Body = bytes.NewBuffer(data)
+	ro.Headers["X-Request-Id"] = uuid.New().String()
+	ro.Headers["Content-Type"] = "application/json"
+
+	_, err = c.httpRequest(ctx, "POST", c.restEndpoint("/sections", id), ro)
+	return err
+}
+
+// DeleteSection deletes a section together with its tasks.
+func (c *Client) DeleteSection(id ID) error {
+	return c.DeleteSectionContext(context.Background(), id)
+}
+
+func (c *Client) DeleteSectionContext(ctx context.Context, id ID) error {
+	_, err := c.httpRequest(ctx, "DELETE", c.restEndpoint("/sections", id), nil)
+	return err
+}
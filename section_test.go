@@ -0,0 +1,80 @@
+package todoist
+
+import (
+	"testing"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestSection(t *testing.T) {
+	c, s := newTestClient(t)
+	project := s.AddProject("Work")
+	s.AddSection(project.ID, "Backlog")
+
+	section, err := c.AddSection(&AddSectionParams{Name: "Doing", ProjectID: ID(project.ID)})
+	if err != nil {
+		t.Fatalf("Failed to create a section: %s", err)
+	}
+	if section.ProjectID != ID(project.ID) || section.Order != 2 {
+		t.Fatalf("Failed to create a section: got %+v", section)
+	}
+
+	if err := c.UpdateSection(section.ID, "In progress"); err != nil {
+		t.Fatalf("Failed to rename the section: %s", err)
+	}
+	if section, err = c.GetSection(section.ID); err != nil || section.Name != "In progress" {
+		t.Fatalf("Failed to rename the section: %v, %+v", err, section)
+	}
+
+	list, err := c.ListSections(ID(project.ID))
+	if err != nil {
+		t.Fatalf("Failed to list sections: %s", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 sections, got %+v", list)
+	}
+
+	task := s.AddTask(todoisttest.Task{Content: "write report"})
+	if err := c.MoveTaskWithParams(ID(task.ID), &MoveTaskParams{SectionID: section.ID}); err != nil {
+		t.Fatalf("Failed to move the task to the section: %s", err)
+	}
+	moved, err := c.GetTask(ID(task.ID))
+	if err != nil {
+		t.Fatalf("Failed to get the task: %s", err)
+	}
+	if moved.SectionID != section.ID || moved.ProjectID != ID(project.ID) {
+		t.Fatalf("Failed to move the task to the section: got %+v", moved)
+	}
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if synced, _ := e.Task(moved.ID); synced.SectionID != section.ID {
+		t.Fatalf("Expected the synced task in the section, got %+v", synced)
+	}
+	if n := len(e.Sections(ID(project.ID))); n != 2 {
+		t.Fatalf("Expected 2 synced sections, got %d", n)
+	}
+
+	if err := c.DeleteSection(section.ID); err != nil {
+		t.Fatalf("Failed to delete the section: %s", err)
+	}
+	if _, ok := s.Task(task.ID); ok {
+		t.Fatal("Expected the tasks of the section to be deleted")
+	}
+
+	if err := (&MoveTaskParams{ProjectID: ID(project.ID), SectionID: section.ID}).Validate(); err == nil {
+		t.Fatal("Expected a validation error for a move to both a project and a section")
+	}
+}
+
+func TestUnifiedSection(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	project := s.AddProject("Work")
+
+	s.AddSection(project.ID, "Doing")
+	if sections, err := c.ListSections(ID(project.ID)); err != nil || len(sections) != 1 || sections[0].Order != 1 {
+		t.Fatalf("Failed to list sections: %v, %+v", err, sections)
+	}
+}
@@ -0,0 +1,90 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Stats is the productivity of the user as shown in Todoist's karma page.
+type Stats struct {
+	Karma      float64 `json:"karma"`
+	KarmaTrend string  `json:"karma_trend"`
+	// CompletedCount is the number of tasks the user ever completed.
+	CompletedCount int `json:"completed_count"`
+	// Days and Weeks hold the number of tasks completed in the recent days
+	// and weeks, most recent first.
+	Days  []*DayStats  `json:"days_items"`
+	Weeks []*WeekStats `json:"week_items"`
+	Goals Goals        `json:"goals"`
+}
+
+type DayStats struct {
+	Date           string `json:"date"`
+	TotalCompleted int    `json:"total_completed"`
+}
+
+type WeekStats struct {
+	From           string `json:"from"`
+	To             string `json:"to"`
+	TotalCompleted int    `json:"total_completed"`
+}
+
+// Goals are the daily and weekly goals of the user and the streaks of
+// reaching them.
+type Goals struct {
+	DailyGoal           int    `json:"daily_goal"`
+	WeeklyGoal          int    `json:"weekly_goal"`
+	CurrentDailyStreak  Streak `json:"current_daily_streak"`
+	CurrentWeeklyStreak Streak `json:"current_weekly_streak"`
+	MaxDailyStreak      Streak `json:"max_daily_streak"`
+	MaxWeeklyStreak     Streak `json:"max_weekly_streak"`
+	// IgnoreDays are the days of the week, 1 for Monday, that do not break
+	// the daily streak.
+	IgnoreDays   []int `json:"ignore_days"`
+	VacationMode bool  `json:"vacation_mode"`
+}
+
+// UnmarshalJSON accepts the vacation mode as a 0/1 integer as well.
+func (g *Goals) UnmarshalJSON(data []byte) error {
+	type goals Goals
+	aux := struct {
+		*goals
+		VacationMode *flag `json:"vacation_mode"`
+	}{goals: (*goals)(g)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.VacationMode != nil {
+		g.VacationMode = bool(*aux.VacationMode)
+	}
+	return nil
+}
+
+// Streak is a run of consecutive days or weeks the goal was reached.
+type Streak struct {
+	Count int    `json:"count"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// GetStats returns the productivity stats of the user.
+func (c *Client) GetStats() (*Stats, error) {
+	return c.GetStatsContext(context.Background())
+}
+
+func (c *Client) GetStatsContext(ctx context.Context) (*Stats, error) {
+	u := c.syncEndpoint("/completed/get_stats")
+	if c.apiVersion == UnifiedAPI {
+		u = c.restEndpoint("/tasks/completed/stats")
+	}
+
+	resp, err := c.httpRequest(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Stats)
+	return out, decodeJSON(resp, out)
+}
@@ -0,0 +1,67 @@
+package todoist
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestStats(t *testing.T) {
+	c, s := newTestClient(t)
+	s.DailyGoal = 2
+
+	today := time.Now().UTC().Truncate(24 * time.Hour).Add(time.Minute)
+	for _, daysAgo := range []int{0, 0, 1, 1, 3, 3, 3} {
+		task := s.AddTask(todoisttest.Task{Content: "done"})
+		s.CompleteTask(task.ID, today.AddDate(0, 0, -daysAgo))
+	}
+
+	stats, err := c.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get the stats: %s", err)
+	}
+	if stats.CompletedCount != 7 || stats.Karma == 0 {
+		t.Fatalf("Unexpected totals %+v", stats)
+	}
+	if len(stats.Days) != 7 || stats.Days[0].TotalCompleted != 2 || stats.Days[2].TotalCompleted != 0 || stats.Days[3].TotalCompleted != 3 {
+		t.Fatalf("Unexpected daily counts %+v", stats.Days)
+	}
+	if len(stats.Weeks) != 4 || stats.Weeks[0].From == "" {
+		t.Fatalf("Unexpected weekly counts %+v", stats.Weeks)
+	}
+
+	goals := stats.Goals
+	if goals.DailyGoal != 2 || goals.WeeklyGoal != 25 {
+		t.Fatalf("Unexpected goals %+v", goals)
+	}
+	if goals.CurrentDailyStreak.Count != 2 || goals.CurrentDailyStreak.End != today.Format("2006-01-02") {
+		t.Fatalf("Unexpected current daily streak %+v", goals.CurrentDailyStreak)
+	}
+	if goals.MaxDailyStreak.Count != 2 {
+		t.Fatalf("Unexpected max daily streak %+v", goals.MaxDailyStreak)
+	}
+}
+
+func TestGoalsUnmarshal(t *testing.T) {
+	var goals Goals
+	if err := json.Unmarshal([]byte(`{"daily_goal":5,"vacation_mode":1,"ignore_days":[6,7]}`), &goals); err != nil {
+		t.Fatalf("Failed to decode goals: %s", err)
+	}
+	if goals.DailyGoal != 5 || !goals.VacationMode || len(goals.IgnoreDays) != 2 {
+		t.Fatalf("Unexpected goals %+v", goals)
+	}
+}
+
+func TestUnifiedStats(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	for i := 0; i < 3; i++ {
+		task := s.AddTask(todoisttest.Task{Content: "done"})
+		s.CompleteTask(task.ID, time.Now().Add(-time.Hour))
+	}
+
+	if stats, err := c.GetStats(); err != nil || stats.CompletedCount != 3 || len(stats.Days) != 7 {
+		t.Fatalf("Expected the stats of 3 completed tasks, got %v, %+v", err, stats)
+	}
+}
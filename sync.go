@@ -0,0 +1,552 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/tucnak/store"
+)
+
+var syncResourceTypes = []string{"user", "items", "projects", "labels", "sections", "notes", "reminders", "filters"}
+
+// syncCacheVersion is the version of the cache file format. Bump it whenever
+// the cached objects gain fields, so that existing caches are refilled by a
+// full sync instead of waiting for each object to change on the server.
+const syncCacheVersion = 1
+
+type User struct {
+	ID        ID     `json:"id"`
+	FullName  string `json:"full_name"`
+	Email     string `json:"email"`
+	IsPremium bool   `json:"is_premium"`
+	TZInfo    struct {
+		Timezone string `json:"timezone"`
+	} `json:"tz_info"`
+}
+
+// Note is a task comment as delivered by the Sync API.
+type Note struct {
+	ID       ID     `json:"id"`
+	TaskID   ID     `json:"item_id"`
+	Content  string `json:"content"`
+	Posted   string `json:"posted"`
+	PosterID ID     `json:"posted_uid,omitempty"`
+}
+
+// flag decodes the Sync API's 0/1 integers as well as JSON booleans.
+type flag bool
+
+func (f *flag) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "1", "true":
+		*f = true
+	case "0", "false", "null":
+		*f = false
+	default:
+		return fmt.Errorf("invalid flag value: %s", data)
+	}
+	return nil
+}
+
+type syncItem struct {
+	ID         ID        `json:"id"`
+	Content    string    `json:"content"`
+	ProjectID  ID        `json:"project_id"`
+	SectionID  ID        `json:"section_id"`
+	ParentID   ID        `json:"parent_id"`
+	Labels     labelList `json:"labels"`
+	Priority   uint      `json:"priority"`
+	ChildOrder uint      `json:"child_order"`
+	Due        *Due      `json:"due"`
+	Checked    flag      `json:"checked"`
+	IsDeleted  flag      `json:"is_deleted"`
+
+	ResponsibleUID ID `json:"responsible_uid"`
+	AssignedByUID  ID `json:"assigned_by_uid"`
+
+	Description string    `json:"description"`
+	Duration    *Duration `json:"duration"`
+	Deadline    *Deadline `json:"deadline"`
+	// DateAdded is called added_at by the unified API.
+	DateAdded  string `json:"date_added"`
+	AddedAt    string `json:"added_at"`
+	AddedByUID ID     `json:"added_by_uid"`
+}
+
+func (i *syncItem) task() *Task {
+	t := &Task{
+		ID:        i.ID,
+		Content:   i.Content,
+		ProjectID: i.ProjectID,
+		SectionID: i.SectionID,
+		ParentID:  i.ParentID,
+		LabelIDs:  i.Labels.IDs,
+		Labels:    i.Labels.Names,
+		Priority:  i.Priority,
+		Order:     i.ChildOrder,
+		URL:       fmt.Sprintf("https://todoist.com/showTask?id=%s", i.ID),
+
+		AssigneeID: i.ResponsibleUID,
+		AssignerID: i.AssignedByUID,
+
+		Description: i.Description,
+		Duration:    i.Duration,
+		Deadline:    i.Deadline,
+		CreatedAt:   i.DateAdded,
+		CreatorID:   i.AddedByUID,
+	}
+	if i.AddedAt != "" {
+		t.CreatedAt = i.AddedAt
+	}
+	if t.LabelIDs == nil {
+		t.LabelIDs = []ID{}
+	}
+	if i.Due != nil {
+		t.Due = *i.Due
+	}
+	return t
+}
+
+type syncProject struct {
+	ID           ID     `json:"id"`
+	ParentID     ID     `json:"parent_id"`
+	Name         string `json:"name"`
+	Color        Color  `json:"color"`
+	ChildOrder   uint   `json:"child_order"`
+	IsFavorite   flag   `json:"is_favorite"`
+	InboxProject flag   `json:"inbox_project"`
+	IsDeleted    flag   `json:"is_deleted"`
+	IsArchived   flag   `json:"is_archived"`
+}
+
+func (p *syncProject) project() *Project {
+	return &Project{
+		ID:           p.ID,
+		ParentID:     p.ParentID,
+		Name:         p.Name,
+		Color:        p.Color,
+		Order:        p.ChildOrder,
+		Favorite:     bool(p.IsFavorite),
+		InboxProject: bool(p.InboxProject),
+	}
+}
+
+type syncLabel struct {
+	ID         ID     `json:"id"`
+	Name       string `json:"name"`
+	Color      Color  `json:"color"`
+	ItemOrder  uint   `json:"item_order"`
+	IsFavorite flag   `json:"is_favorite"`
+	IsDeleted  flag   `json:"is_deleted"`
+}
+
+type syncSection struct {
+	ID           ID     `json:"id"`
+	Name         string `json:"name"`
+	ProjectID    ID     `json:"project_id"`
+	SectionOrder uint   `json:"section_order"`
+	IsDeleted    flag   `json:"is_deleted"`
+	IsArchived   flag   `json:"is_archived"`
+}
+
+type syncNote struct {
+	Note
+	PostedAt  string `json:"posted_at"`
+	IsDeleted flag   `json:"is_deleted"`
+}
+
+type syncReminder struct {
+	Reminder
+	IsDeleted flag `json:"is_deleted"`
+}
+
+type syncFilter struct {
+	ID         ID     `json:"id"`
+	Name       string `json:"name"`
+	Query      string `json:"query"`
+	Color      Color  `json:"color"`
+	ItemOrder  uint   `json:"item_order"`
+	IsFavorite flag   `json:"is_favorite"`
+	IsDeleted  flag   `json:"is_deleted"`
+}
+
+func (f *syncFilter) filter() *Filter {
+	return &Filter{ID: f.ID, Name: f.Name, Query: f.Query, Color: f.Color, Order: f.ItemOrder, Favorite: bool(f.IsFavorite)}
+}
+
+type syncResponse struct {
+	SyncToken string          `json:"sync_token"`
+	FullSync  bool            `json:"full_sync"`
+	User      *User           `json:"user"`
+	Items     []*syncItem     `json:"items"`
+	Projects  []*syncProject  `json:"projects"`
+	Labels    []*syncLabel    `json:"labels"`
+	Sections  []*syncSection  `json:"sections"`
+	Notes     []*syncNote     `json:"notes"`
+	Reminders []*syncReminder `json:"reminders"`
+	Filters   []*syncFilter   `json:"filters"`
+}
+
+// syncState is the local replica of the account. It is also the format of
+// the cache file.
+type syncState struct {
+	Version       int      `json:"version"`
+	ResourceTypes []string `json:"resource_types"`
+
+	SyncToken string           `json:"sync_token"`
+	User      *User            `json:"user,omitempty"`
+	Tasks     map[ID]*Task     `json:"tasks"`
+	Projects  map[ID]*Project  `json:"projects"`
+	Labels    map[ID]*Label    `json:"labels"`
+	Sections  map[ID]*Section  `json:"sections"`
+	Notes     map[ID]*Note     `json:"notes"`
+	Reminders map[ID]*Reminder `json:"reminders"`
+	Filters   map[ID]*Filter   `json:"filters"`
+}
+
+func newSyncState() *syncState {
+	return &syncState{
+		Version:       syncCacheVersion,
+		ResourceTypes: syncResourceTypes,
+
+		SyncToken: "*",
+		Tasks:     map[ID]*Task{},
+		Projects:  map[ID]*Project{},
+		Labels:    map[ID]*Label{},
+		Sections:  map[ID]*Section{},
+		Notes:     map[ID]*Note{},
+		Reminders: map[ID]*Reminder{},
+		Filters:   map[ID]*Filter{},
+	}
+}
+
+func (s *syncState) apply(resp *syncResponse) {
+	if resp.User != nil {
+		s.User = resp.User
+	}
+
+	for _, v := range resp.Items {
+		if v.IsDeleted || v.Checked {
+			delete(s.Tasks, v.ID)
+		} else {
+			s.Tasks[v.ID] = v.task()
+		}
+	}
+
+	for _, v := range resp.Projects {
+		if v.IsDeleted || v.IsArchived {
+			delete(s.Projects, v.ID)
+		} else {
+			s.Projects[v.ID] = v.project()
+		}
+	}
+
+	for _, v := range resp.Labels {
+		if v.IsDeleted {
+			delete(s.Labels, v.ID)
+		} else {
+			s.Labels[v.ID] = &Label{ID: v.ID, Name: v.Name, Color: v.Color, Order: v.ItemOrder, Favorite: bool(v.IsFavorite)}
+		}
+	}
+
+	for _, v := range resp.Sections {
+		if v.IsDeleted || v.IsArchived {
+			delete(s.Sections, v.ID)
+		} else {
+			s.Sections[v.ID] = &Section{ID: v.ID, Name: v.Name, ProjectID: v.ProjectID, Order: v.SectionOrder}
+		}
+	}
+
+	for _, v := range resp.Notes {
+		if v.IsDeleted {
+			delete(s.Notes, v.ID)
+		} else {
+			note := v.Note
+			if v.PostedAt != "" {
+				note.Posted = v.PostedAt
+			}
+			s.Notes[v.ID] = &note
+		}
+	}
+
+	for _, v := range resp.Reminders {
+		if v.IsDeleted {
+			delete(s.Reminders, v.ID)
+		} else {
+			reminder := v.Reminder
+			s.Reminders[v.ID] = &reminder
+		}
+	}
+
+	for _, v := range resp.Filters {
+		if v.IsDeleted {
+			delete(s.Filters, v.ID)
+		} else {
+			s.Filters[v.ID] = v.filter()
+		}
+	}
+
+	for _, t := range s.Tasks {
+		t.CommentCount = 0
+	}
+	for _, n := range s.Notes {
+		if t, ok := s.Tasks[n.TaskID]; ok {
+			t.CommentCount++
+		}
+	}
+
+	s.SyncToken = resp.SyncToken
+}
+
+// SyncEngine keeps a local replica of the account using the Sync API. The
+// first Sync downloads everything; later ones only apply what changed since
+// the stored sync token.
+type SyncEngine struct {
+	client *Client
+
+	// CacheFile is the store file the state is persisted to after every
+	// sync and loaded from by Load. Empty disables persistence.
+	CacheFile string
+
+	mu    sync.RWMutex
+	state *syncState
+}
+
+func NewSyncEngine(client *Client) *SyncEngine {
+	return &SyncEngine{
+		client: client,
+		state:  newSyncState(),
+	}
+}
+
+// Load restores the state saved in CacheFile by a previous run. A cache
+// written by another version or for other resource types is kept for display,
+// but its sync token is discarded so that the next Sync is a full one. An
+// unreadable cache is logged and discarded, since a full sync rebuilds it.
+func (e *SyncEngine) Load() error {
+	if e.CacheFile == "" {
+		return nil
+	}
+
+	state := newSyncState()
+	if err := store.Load(e.CacheFile, state); err != nil {
+		e.client.Logger.Printf("Discarding the sync cache: %s", err)
+		e.Reset()
+		return nil
+	}
+	if state.SyncToken == "" || state.Version != syncCacheVersion || !equalStrings(state.ResourceTypes, syncResourceTypes) {
+		state.Version = syncCacheVersion
+		state.ResourceTypes = syncResourceTypes
+		state.SyncToken = "*"
+	}
+
+	e.mu.Lock()
+	e.state = state
+	e.mu.Unlock()
+
+	return nil
+}
+
+// Reset discards the local state so that the next Sync is a full one.
+func (e *SyncEngine) Reset() {
+	e.mu.Lock()
+	e.state = newSyncState()
+	e.mu.Unlock()
+}
+
+func (e *SyncEngine) Sync() error {
+	return e.SyncContext(context.Background())
+}
+
+func (e *SyncEngine) SyncContext(ctx context.Context) error {
+	resourceTypes, _ := json.Marshal(syncResourceTypes)
+
+	params := url.Values{}
+	params.Add("sync_token", e.SyncToken())
+	params.Add("resource_types", string(resourceTypes))
+
+	resp := new(syncResponse)
+	if err := e.client.sync(ctx, params, resp); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if resp.FullSync {
+		e.state = newSyncState()
+	}
+	e.state.apply(resp)
+
+	if e.CacheFile != "" {
+		return store.Save(e.CacheFile, e.state)
+	}
+	return nil
+}
+
+func (e *SyncEngine) SyncToken() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.state.SyncToken
+}
+
+func (e *SyncEngine) User() *User {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.state.User == nil {
+		return &User{}
+	}
+	u := *e.state.User
+	return &u
+}
+
+// Tasks returns copies of the active tasks accepted by filter, ordered by
+// project and position. A nil filter accepts every task.
+func (e *SyncEngine) Tasks(filter func(*Task) bool) []*Task {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	list := []*Task{}
+	for _, t := range e.state.Tasks {
+		if filter == nil || filter(t) {
+			v := *t
+			list = append(list, &v)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		pi, pj := e.projectOrder(list[i].ProjectID), e.projectOrder(list[j].ProjectID)
+		if pi != pj {
+			return pi < pj
+		}
+		if list[i].Order != list[j].Order {
+			return list[i].Order < list[j].Order
+		}
+		return list[i].ID.less(list[j].ID)
+	})
+	return list
+}
+
+func (e *SyncEngine) projectOrder(id ID) uint {
+	if p, ok := e.state.Projects[id]; ok {
+		return p.Order
+	}
+	return 0
+}
+
+func (e *SyncEngine) Task(id ID) (*Task, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	t, ok := e.state.Tasks[id]
+	if !ok {
+		return nil, false
+	}
+	v := *t
+	return &v, true
+}
+
+func (e *SyncEngine) Projects() []*Project {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	list := []*Project{}
+	for _, p := range e.state.Projects {
+		v := *p
+		list = append(list, &v)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Order < list[j].Order })
+	return list
+}
+
+func (e *SyncEngine) Labels() []*Label {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	list := []*Label{}
+	for _, l := range e.state.Labels {
+		v := *l
+		list = append(list, &v)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Order < list[j].Order })
+	return list
+}
+
+// Sections returns the sections of a project ordered by position.
+func (e *SyncEngine) Sections(projectID ID) []*Section {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	list := []*Section{}
+	for _, s := range e.state.Sections {
+		if s.ProjectID == projectID {
+			v := *s
+			list = append(list, &v)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Order < list[j].Order })
+	return list
+}
+
+// Notes returns the comments of a task ordered by ID.
+func (e *SyncEngine) Notes(taskID ID) []*Note {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	list := []*Note{}
+	for _, n := range e.state.Notes {
+		if n.TaskID == taskID {
+			v := *n
+			list = append(list, &v)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID.less(list[j].ID) })
+	return list
+}
+
+// Reminders returns the reminders of a task ordered by ID.
+func (e *SyncEngine) Reminders(taskID ID) []*Reminder {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	list := []*Reminder{}
+	for _, r := range e.state.Reminders {
+		if r.TaskID == taskID {
+			v := *r
+			list = append(list, &v)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID.less(list[j].ID) })
+	return list
+}
+
+// Filters returns the saved filters ordered by position.
+func (e *SyncEngine) Filters() []*Filter {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	list := []*Filter{}
+	for _, f := range e.state.Filters {
+		v := *f
+		list = append(list, &v)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Order != list[j].Order {
+			return list[i].Order < list[j].Order
+		}
+		return list[i].ID.less(list[j].ID)
+	})
+	return list
+}
@@ -0,0 +1,164 @@
+package todoist
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haccht/todoist/todoisttest"
+	"github.com/tucnak/store"
+)
+
+func TestSyncEngine(t *testing.T) {
+	c, s := newTestClient(t)
+	project := s.AddProject("Work")
+	label := s.AddLabel("urgent")
+	kept := s.AddTask(todoisttest.Task{Content: "kept", ProjectID: project.ID, LabelIDs: []todoisttest.ID{label.ID}})
+	removed := s.AddTask(todoisttest.Task{Content: "removed"})
+	s.AddComment(todoisttest.Comment{TaskID: kept.ID, Content: "note"})
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+
+	if n := len(e.Tasks(nil)); n != 2 {
+		t.Fatalf("Expected 2 tasks after the full sync, got %d", n)
+	}
+	if n := len(e.Projects()); n != 2 {
+		t.Fatalf("Expected 2 projects after the full sync, got %d", n)
+	}
+	if n := len(e.Labels()); n != 1 {
+		t.Fatalf("Expected 1 label after the full sync, got %d", n)
+	}
+	if task, _ := e.Task(ID(kept.ID)); task.CommentCount != 1 || len(e.Notes(ID(kept.ID))) != 1 {
+		t.Fatalf("Expected the note to be synced, got %+v", task)
+	}
+
+	token := e.SyncToken()
+	added, err := c.AddTask(&map[string]interface{}{"content": "added"})
+	if err != nil {
+		t.Fatalf("Failed to create a task: %s", err)
+	}
+	s.DeleteTask(removed.ID)
+	s.UpdateTask(kept.ID, func(v *todoisttest.Task) { v.Content = "changed" })
+
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync incrementally: %s", err)
+	}
+
+	if _, ok := e.Task(ID(removed.ID)); ok {
+		t.Fatal("Expected the deleted task to be removed")
+	}
+	if _, ok := e.Task(added.ID); !ok {
+		t.Fatal("Expected the added task to be synced")
+	}
+	if task, _ := e.Task(ID(kept.ID)); task.Content != "changed" {
+		t.Fatalf("Expected the updated task to be synced, got %q", task.Content)
+	}
+	if e.SyncToken() == token {
+		t.Fatal("Expected the sync token to advance")
+	}
+
+	tasks := e.Tasks(func(t *Task) bool { return t.ProjectID == ID(project.ID) })
+	if len(tasks) != 1 || tasks[0].ID != ID(kept.ID) {
+		t.Fatalf("Failed to filter tasks by project: %+v", tasks)
+	}
+}
+
+func TestSyncEngineIncrementalRequest(t *testing.T) {
+	c, s := newTestClient(t)
+	var tokens []string
+	s.Hook = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/sync/v8/sync" {
+			r.ParseForm()
+			tokens = append(tokens, r.PostForm.Get("sync_token"))
+		}
+		return false
+	}
+
+	e := NewSyncEngine(c)
+	for i := 0; i < 2; i++ {
+		if err := e.Sync(); err != nil {
+			t.Fatalf("Failed to sync: %s", err)
+		}
+	}
+
+	if len(tokens) != 2 || tokens[0] != "*" || tokens[1] == "*" {
+		t.Fatalf("Expected a full sync followed by an incremental one, got tokens %v", tokens)
+	}
+}
+
+func TestSyncEngineCache(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store.Init("todoist")
+
+	c, s := newTestClient(t)
+	item := s.AddTask(todoisttest.Task{Content: "cached"})
+
+	e := NewSyncEngine(c)
+	e.CacheFile = "sync.json"
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+
+	restored := NewSyncEngine(c)
+	restored.CacheFile = "sync.json"
+	if err := restored.Load(); err != nil {
+		t.Fatalf("Failed to load the cache: %s", err)
+	}
+
+	if restored.SyncToken() != e.SyncToken() {
+		t.Fatalf("Expected sync token %q, got %q", e.SyncToken(), restored.SyncToken())
+	}
+	if task, ok := restored.Task(ID(item.ID)); !ok || task.Content != "cached" {
+		t.Fatal("Expected the cached task to be restored")
+	}
+
+	path := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "todoist", "sync.json")
+	if err := ioutil.WriteFile(path, []byte(`{"version": 1, "sync_token": "4`), 0600); err != nil {
+		t.Fatalf("Failed to truncate the cache: %s", err)
+	}
+	restored = NewSyncEngine(c)
+	restored.CacheFile = "sync.json"
+	if err := restored.Load(); err != nil {
+		t.Fatalf("Expected a corrupt cache to be discarded, got %s", err)
+	}
+	if token := restored.SyncToken(); token != "*" {
+		t.Fatalf("Expected a corrupt cache to be fully synced, got token %q", token)
+	}
+	if err := restored.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+
+	for _, stale := range []func(*syncState){
+		func(state *syncState) { state.Version = syncCacheVersion - 1 },
+		func(state *syncState) { state.ResourceTypes = []string{"user", "items"} },
+	} {
+		state := newSyncState()
+		if err := store.Load("sync.json", state); err != nil {
+			t.Fatalf("Failed to read the cache: %s", err)
+		}
+		stale(state)
+		if err := store.Save("sync.json", state); err != nil {
+			t.Fatalf("Failed to write the cache: %s", err)
+		}
+
+		restored = NewSyncEngine(c)
+		restored.CacheFile = "sync.json"
+		if err := restored.Load(); err != nil {
+			t.Fatalf("Failed to load the cache: %s", err)
+		}
+		if token := restored.SyncToken(); token != "*" {
+			t.Fatalf("Expected a stale cache to be fully synced, got token %q", token)
+		}
+		if _, ok := restored.Task(ID(item.ID)); !ok {
+			t.Fatal("Expected a stale cache to be kept until the next sync")
+		}
+		if err := restored.Sync(); err != nil {
+			t.Fatalf("Failed to sync: %s", err)
+		}
+	}
+}
@@ -2,80 +2,212 @@ package todoist
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Task struct {
-	ID           uint   `json:"id"`
-	Content      string `json:"content"`
-	ProjectID    uint   `json:"project_id"`
-	LabelIDs     []uint `json:"label_ids"`
-	Priority     uint   `json:"priority"`
-	Completed    bool   `json:"completed"`
-	CommentCount uint   `json:"comment_count"`
-	Order        uint   `json:"order"`
-	Indent       uint   `json:"indent"`
-	URL          string `json:"url"`
-	Due          struct {
-		Date      string `json:"date,omitempty"`
-		Datetime  string `json:"datetime,omitempty"`
-		Recurring bool   `json:"recurring,omitempty"`
-		String    string `json:"string,omitempty"`
-		Timezone  string `json:"timezone,omitempty"`
-	} `json:"due"`
-}
-
-func (t *Task) DueTime() time.Time {
+	ID          ID     `json:"id"`
+	Content     string `json:"content"`
+	Description string `json:"description,omitempty"`
+	ProjectID   ID     `json:"project_id"`
+	SectionID   ID     `json:"section_id,omitempty"`
+	ParentID    ID     `json:"parent_id,omitempty"`
+	LabelIDs    []ID   `json:"label_ids"`
+	// Labels holds label names, which the unified API returns instead of
+	// label IDs.
+	Labels       []string `json:"labels,omitempty"`
+	Priority     uint     `json:"priority"`
+	Completed    bool     `json:"completed"`
+	CommentCount uint     `json:"comment_count"`
+	Order        uint     `json:"order"`
+	// Indent is the nesting level reported by REST v1.
+	//
+	// Deprecated: the API no longer fills it in. Use ParentID or TaskTree.
+	Indent uint   `json:"indent,omitempty"`
+	URL    string `json:"url"`
+	Due    Due    `json:"due"`
+	// AssigneeID is the collaborator responsible for the task in a shared
+	// project, and AssignerID the user who assigned it.
+	AssigneeID ID `json:"assignee,omitempty"`
+	AssignerID ID `json:"assigner,omitempty"`
+	// Duration is how long the task is expected to take from its due time.
+	Duration *Duration `json:"duration,omitempty"`
+	// Deadline is the date the task must be done by, unlike Due, which is
+	// when it is planned.
+	Deadline  *Deadline `json:"deadline,omitempty"`
+	CreatedAt string    `json:"created_at,omitempty"`
+	CreatorID ID        `json:"creator_id,omitempty"`
+}
+
+// Duration units.
+const (
+	DurationMinute = "minute"
+	DurationDay    = "day"
+)
+
+type Duration struct {
+	Amount int    `json:"amount"`
+	Unit   string `json:"unit"`
+}
+
+// String describes the duration, e.g. "90 minutes", "2 hours" or "1 day".
+func (d *Duration) String() string {
 	switch {
-	case t.Due.Datetime != "":
-		time, _ := time.Parse("2006-01-02T15:04:05Z", t.Due.Datetime)
-		return time
-	case t.Due.Date != "":
-		time, _ := time.Parse("2006-01-02", t.Due.Date)
-		return time
+	case d.Unit == DurationDay:
+		return plural(d.Amount, "day")
+	case d.Amount != 0 && d.Amount%60 == 0:
+		return plural(d.Amount/60, "hour")
 	default:
-		zero := new(time.Time)
-		return *zero
+		return plural(d.Amount, "minute")
 	}
 }
 
-func (t *Task) DueString() string {
-	switch {
-	case t.Due.Datetime != "":
-		return t.DueTime().Format("2006-01-02(Mon) 15:04")
-	case t.Due.Date != "":
-		return t.DueTime().Format("2006-01-02(Mon)")
-	default:
-		return ""
+type Deadline struct {
+	Date string `json:"date"`
+	Lang string `json:"lang,omitempty"`
+}
+
+// UnmarshalJSON accepts tasks of both API generations, which name some
+// fields differently.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type task Task
+	aux := struct {
+		*task
+		Checked       *flag     `json:"checked"`
+		ChildOrder    *uint     `json:"child_order"`
+		NoteCount     *uint     `json:"note_count"`
+		Labels        labelList `json:"labels"`
+		ResponsibleID *ID       `json:"responsible_uid"`
+		AssignedByID  *ID       `json:"assigned_by_uid"`
+		Created       *string   `json:"created"`
+		DateAdded     *string   `json:"date_added"`
+		AddedAt       *string   `json:"added_at"`
+		Creator       *ID       `json:"creator"`
+		AddedByID     *ID       `json:"added_by_uid"`
+	}{task: (*task)(t)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Checked != nil {
+		t.Completed = bool(*aux.Checked)
+	}
+	if aux.ChildOrder != nil {
+		t.Order = *aux.ChildOrder
+	}
+	if aux.NoteCount != nil {
+		t.CommentCount = *aux.NoteCount
+	}
+	if len(aux.Labels.IDs) > 0 {
+		t.LabelIDs = aux.Labels.IDs
+	}
+	t.Labels = aux.Labels.Names
+	if t.SectionID == "0" {
+		// REST v1 reports tasks outside any section as section 0.
+		t.SectionID = ""
+	}
+	if t.ParentID == "0" {
+		t.ParentID = ""
+	}
+	if aux.ResponsibleID != nil {
+		t.AssigneeID = *aux.ResponsibleID
+	}
+	if aux.AssignedByID != nil {
+		t.AssignerID = *aux.AssignedByID
+	}
+	if t.AssigneeID == "0" {
+		t.AssigneeID = ""
+	}
+	if t.AssignerID == "0" {
+		t.AssignerID = ""
+	}
+	// REST v1 calls the creation time created, Sync v8 date_added and the
+	// unified API added_at.
+	for _, v := range []*string{aux.Created, aux.DateAdded, aux.AddedAt} {
+		if v != nil {
+			t.CreatedAt = *v
+		}
+	}
+	for _, v := range []*ID{aux.Creator, aux.AddedByID} {
+		if v != nil {
+			t.CreatorID = *v
+		}
+	}
+	if t.CreatorID == "0" {
+		t.CreatorID = ""
 	}
+
+	return nil
 }
 
-func (t *Task) IsOverdue() bool {
-	switch {
-	case t.Due.Datetime != "":
-		return t.DueTime().Before(time.Now())
-	case t.Due.Date != "":
-		return t.DueTime().Truncate(24 * time.Hour).Before(time.Now().Truncate(24 * time.Hour))
-	default:
-		return false
+// TaskNode is a task together with its subtasks.
+type TaskNode struct {
+	*Task
+	Children []*TaskNode
+}
+
+// Walk calls fn for the node and all its descendants in depth-first order.
+// The depth of the node itself is 0.
+func (n *TaskNode) Walk(fn func(node *TaskNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *TaskNode) walk(fn func(node *TaskNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
 	}
 }
 
-func (t *Task) IsDuedate() bool {
-	switch {
-	case t.Due.Datetime != "", t.Due.Date != "":
-		return t.DueTime().Truncate(24 * time.Hour).Equal(time.Now().Truncate(24 * time.Hour))
-	default:
-		return false
+// TaskTree arranges tasks by their ParentID and returns the top-level tasks
+// in the order they have in tasks. Subtasks are sorted by Order, and tasks
+// whose parent is not in the list are treated as top-level ones.
+func TaskTree(tasks []*Task) []*TaskNode {
+	nodes := map[ID]*TaskNode{}
+	for _, t := range tasks {
+		nodes[t.ID] = &TaskNode{Task: t}
+	}
+
+	roots := []*TaskNode{}
+	for _, t := range tasks {
+		node := nodes[t.ID]
+		if parent, ok := nodes[t.ParentID]; ok && t.ParentID != t.ID {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	for _, node := range nodes {
+		children := node.Children
+		sort.SliceStable(children, func(i, j int) bool {
+			if children[i].Order != children[j].Order {
+				return children[i].Order < children[j].Order
+			}
+			return children[i].ID.less(children[j].ID)
+		})
 	}
+	return roots
+}
+
+func (t *Task) CreatedTime() time.Time {
+	time, _ := time.Parse(time.RFC3339, t.CreatedAt)
+	return time
 }
 
 func (c *Client) ListTasks(filter *map[string]interface{}) ([]*Task, error) {
+	return c.ListTasksContext(context.Background(), filter)
+}
+
+func (c *Client) ListTasksContext(ctx context.Context, filter *map[string]interface{}) ([]*Task, error) {
 	ro := NewRequestOption()
 	if filter != nil {
 		for k, v := range *filter {
@@ -83,16 +215,22 @@ func (c *Client) ListTasks(filter *map[string]interface{}) ([]*Task, error) {
 		}
 	}
 
-	resp, err := c.httpRequest("GET", restEndpoint("/tasks"), ro)
-	if err != nil {
-		return nil, err
+	u := c.restEndpoint("/tasks")
+	if query, ok := ro.Params["filter"]; ok && c.apiVersion == UnifiedAPI {
+		delete(ro.Params, "filter")
+		ro.Params["query"] = query
+		u = c.restEndpoint("/tasks/filter")
 	}
 
 	out := []*Task{}
-	return out, decodeJSON(resp, &out)
+	return out, c.list(ctx, u, ro, &out)
 }
 
 func (c *Client) AddTask(args *map[string]interface{}) (*Task, error) {
+	return c.AddTaskContext(context.Background(), args)
+}
+
+func (c *Client) AddTaskContext(ctx context.Context, args *map[string]interface{}) (*Task, error) {
 	data, err := json.Marshal(args)
 	if err != nil {
 		return nil, err
@@ -103,7 +241,7 @@ func (c *Client) AddTask(args *map[string]interface{}) (*Task, error) {
 	ro.Headers["X-Request-Id"] = uuid.New().String()
 	ro.Headers["Content-Type"] = "application/json"
 
-	resp, err := c.httpRequest("POST", restEndpoint("/tasks"), ro)
+	resp, err := c.httpRequest(ctx, "POST", c.restEndpoint("/tasks"), ro)
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +250,12 @@ func (c *Client) AddTask(args *map[string]interface{}) (*Task, error) {
 	return out, decodeJSON(resp, out)
 }
 
-func (c *Client) GetTask(id uint) (*Task, error) {
-	resp, err := c.httpRequest("GET", restEndpoint("/tasks", id), nil)
+func (c *Client) GetTask(id ID) (*Task, error) {
+	return c.GetTaskContext(context.Background(), id)
+}
+
+func (c *Client) GetTaskContext(ctx context.Context, id ID) (*Task, error) {
+	resp, err := c.httpRequest(ctx, "GET", c.restEndpoint("/tasks", id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +264,11 @@ func (c *Client) GetTask(id uint) (*Task, error) {
 	return out, decodeJSON(resp, out)
 }
 
-func (c *Client) UpdateTask(id uint, args *map[string]interface{}) error {
+func (c *Client) UpdateTask(id ID, args *map[string]interface{}) error {
+	return c.UpdateTaskContext(context.Background(), id, args)
+}
+
+func (c *Client) UpdateTaskContext(ctx context.Context, id ID, args *map[string]interface{}) error {
 	data, err := json.Marshal(args)
 	if err != nil {
 		return err
@@ -133,45 +279,80 @@ func (c *Client) UpdateTask(id uint, args *map[string]interface{}) error {
 	ro.Headers["X-Request-Id"] = uuid.New().String()
 	ro.Headers["Content-Type"] = "application/json"
 
-	_, err = c.httpRequest("POST", restEndpoint("/tasks", id), ro)
+	_, err = c.httpRequest(ctx, "POST", c.restEndpoint("/tasks", id), ro)
 	return err
 }
 
-func (c *Client) DeleteTask(id uint) error {
-	_, err := c.httpRequest("DELETE", restEndpoint("/tasks", id), nil)
+func (c *Client) DeleteTask(id ID) error {
+	return c.DeleteTaskContext(context.Background(), id)
+}
+
+func (c *Client) DeleteTaskContext(ctx context.Context, id ID) error {
+	_, err := c.httpRequest(ctx, "DELETE", c.restEndpoint("/tasks", id), nil)
 	return err
 }
 
-func (c *Client) CloseTask(id uint) error {
-	_, err := c.httpRequest("POST", restEndpoint("/tasks", id, "/close"), nil)
+func (c *Client) CloseTask(id ID) error {
+	return c.CloseTaskContext(context.Background(), id)
+}
+
+func (c *Client) CloseTaskContext(ctx context.Context, id ID) error {
+	_, err := c.httpRequest(ctx, "POST", c.restEndpoint("/tasks", id, "/close"), nil)
 	return err
 }
 
-func (c *Client) ReopenTask(id uint) error {
-	_, err := c.httpRequest("POST", restEndpoint("/tasks", id, "/reopen"), nil)
+func (c *Client) ReopenTask(id ID) error {
+	return c.ReopenTaskContext(context.Background(), id)
+}
+
+func (c *Client) ReopenTaskContext(ctx context.Context, id ID) error {
+	_, err := c.httpRequest(ctx, "POST", c.restEndpoint("/tasks", id, "/reopen"), nil)
 	return err
 }
 
-func (c *Client) MoveTask(id uint, args *map[string]interface{}) error {
-	commandArgs := map[string]interface{}{"id": id}
+func (c *Client) MoveTask(id ID, args *map[string]interface{}) error {
+	return c.MoveTaskContext(context.Background(), id, args)
+}
+
+func (c *Client) MoveTaskContext(ctx context.Context, id ID, args *map[string]interface{}) error {
+	var extra map[string]interface{}
 	if args != nil {
-		for k, v := range *args {
-			commandArgs[k] = v
-		}
+		extra = *args
 	}
 
-	params := url.Values{}
-	params.Add("commands", makeCommand("item_move", commandArgs))
+	b := c.NewCommandBatch()
+	b.ItemMove(id, extra)
 
-	ro := NewRequestOption()
-	ro.Body = bytes.NewBufferString(params.Encode())
-	ro.Headers["Content-Type"] = "application/x-www-form-urlencoded"
-
-	_, err := c.httpRequest("POST", syncEndpoint("/sync"), ro)
+	_, err := b.FlushContext(ctx)
 	return err
 }
 
 func (c *Client) QuickAddTask(text string, args *map[string]interface{}) error {
+	return c.QuickAddTaskContext(context.Background(), text, args)
+}
+
+func (c *Client) QuickAddTaskContext(ctx context.Context, text string, args *map[string]interface{}) error {
+	if c.apiVersion == UnifiedAPI {
+		body := map[string]interface{}{"text": text}
+		if args != nil {
+			for k, v := range *args {
+				body[k] = v
+			}
+		}
+
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		ro := NewRequestOption()
+		ro.Body = bytes.NewBuffer(data)
+		ro.Headers["Content-Type"] = "application/json"
+
+		_, err = c.httpRequest(ctx, "POST", c.restEndpoint("/tasks/quick"), ro)
+		return err
+	}
+
 	params := url.Values{"text": {text}}
 	if args != nil {
 		for k, v := range *args {
@@ -183,6 +364,71 @@ func (c *Client) QuickAddTask(text string, args *map[string]interface{}) error {
 	ro.Body = bytes.NewBufferString(params.Encode())
 	ro.Headers["Content-Type"] = "application/x-www-form-urlencoded"
 
-	_, err := c.httpRequest("POST", syncEndpoint("/quick/add"), ro)
+	_, err := c.httpRequest(ctx, "POST", c.syncEndpoint("/quick/add"), ro)
 	return err
 }
+
+func (c *Client) ListTasksWithFilter(filter *TaskFilter) ([]*Task, error) {
+	return c.ListTasksWithFilterContext(context.Background(), filter)
+}
+
+func (c *Client) ListTasksWithFilterContext(ctx context.Context, filter *TaskFilter) ([]*Task, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := filter.args()
+	return c.ListTasksContext(ctx, &args)
+}
+
+func (c *Client) AddTaskWithParams(params *AddTaskParams) (*Task, error) {
+	return c.AddTaskWithParamsContext(context.Background(), params)
+}
+
+func (c *Client) AddTaskWithParamsContext(ctx context.Context, params *AddTaskParams) (*Task, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := c.assigneeArgs(params.args())
+	return c.AddTaskContext(ctx, &args)
+}
+
+func (c *Client) UpdateTaskWithParams(id ID, params *UpdateTaskParams) error {
+	return c.UpdateTaskWithParamsContext(context.Background(), id, params)
+}
+
+func (c *Client) UpdateTaskWithParamsContext(ctx context.Context, id ID, params *UpdateTaskParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	args := c.assigneeArgs(params.args())
+	return c.UpdateTaskContext(ctx, id, &args)
+}
+
+func (c *Client) MoveTaskWithParams(id ID, params *MoveTaskParams) error {
+	return c.MoveTaskWithParamsContext(context.Background(), id, params)
+}
+
+func (c *Client) MoveTaskWithParamsContext(ctx context.Context, id ID, params *MoveTaskParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	args := params.args()
+	return c.MoveTaskContext(ctx, id, &args)
+}
+
+func (c *Client) QuickAddTaskWithParams(text string, params *QuickAddParams) error {
+	return c.QuickAddTaskWithParamsContext(context.Background(), text, params)
+}
+
+func (c *Client) QuickAddTaskWithParamsContext(ctx context.Context, text string, params *QuickAddParams) error {
+	if text == "" {
+		return invalid("text", "is required")
+	}
+
+	args := params.args()
+	return c.QuickAddTaskContext(ctx, text, &args)
+}
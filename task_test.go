@@ -1,14 +1,56 @@
 package todoist
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/haccht/todoist/todoisttest"
 )
 
+func newTestClient(t *testing.T) (*Client, *todoisttest.Server) {
+	s := todoisttest.NewServer()
+	s.Token = "test-token"
+	t.Cleanup(s.Close)
+
+	c := NewClient(s.Token,
+		WithRESTBaseURL(s.RESTURL()),
+		WithSyncBaseURL(s.SyncURL()),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}),
+	)
+	if testing.Verbose() {
+		c.Logger = log.New(os.Stdout, "[DEBUG] ", log.LstdFlags)
+	}
+
+	return c, s
+}
+
+// newUnifiedTestClient is newTestClient for the unified API. The server
+// returns pages of two objects so that every list is paginated.
+func newUnifiedTestClient(t *testing.T) (*Client, *todoisttest.Server) {
+	s := todoisttest.NewServer()
+	s.Token = "test-token"
+	s.PageSize = 2
+	t.Cleanup(s.Close)
+
+	c := NewClient(s.Token,
+		WithAPIVersion(UnifiedAPI),
+		WithRESTBaseURL(s.UnifiedURL()),
+		WithSyncBaseURL(s.UnifiedURL()),
+		WithRetryPolicy(NoRetry),
+	)
+	if testing.Verbose() {
+		c.Logger = log.New(os.Stdout, "[DEBUG] ", log.LstdFlags)
+	}
+
+	return c, s
+}
+
 func TestTask(t *testing.T) {
-	c := NewClient(os.Getenv("TODOIST_TOKEN"))
-	c.Logger = log.New(os.Stdout, "[DEBUG] ", log.LstdFlags)
+	c, _ := newTestClient(t)
 
 	item, err := c.AddTask(&map[string]interface{}{"content": "created"})
 	if err != nil {
@@ -58,3 +100,218 @@ func TestTask(t *testing.T) {
 		t.Fatalf("Failed to delete the task: %s", err)
 	}
 }
+
+func TestMoveTask(t *testing.T) {
+	c, s := newTestClient(t)
+	project := s.AddProject("Work")
+	item := s.AddTask(todoisttest.Task{Content: "move me"})
+
+	err := c.MoveTask(ID(item.ID), &map[string]interface{}{"project_id": project.ID})
+	if err != nil {
+		t.Fatalf("Failed to move the task: %s", err)
+	}
+
+	moved, _ := s.Task(item.ID)
+	if moved.ProjectID != project.ID {
+		t.Fatalf("Failed to move the task: project_id is %s, want %s", moved.ProjectID, project.ID)
+	}
+}
+
+func TestQuickAddTask(t *testing.T) {
+	c, s := newTestClient(t)
+	project := s.AddProject("Work")
+	label := s.AddLabel("urgent")
+
+	if err := c.QuickAddTask("write report #Work @urgent", nil); err != nil {
+		t.Fatalf("Failed to quick add a task: %s", err)
+	}
+
+	list, err := c.ListTasks(&map[string]interface{}{"project_id": project.ID})
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %s", err)
+	}
+	if len(list) != 1 || list[0].Content != "write report" {
+		t.Fatalf("Failed to quick add a task: got %+v", list)
+	}
+	if len(list[0].LabelIDs) != 1 || list[0].LabelIDs[0] != ID(label.ID) {
+		t.Fatalf("Failed to quick add a task: labels %v, want [%s]", list[0].LabelIDs, label.ID)
+	}
+}
+
+func TestTaskTree(t *testing.T) {
+	tasks := []*Task{
+		{ID: "3", Content: "Write report"},
+		{ID: "4", Content: "Outline", ParentID: "3", Order: 2},
+		{ID: "5", Content: "Collect data", ParentID: "3", Order: 1},
+		{ID: "6", Content: "Charts", ParentID: "5", Order: 1},
+		{ID: "1", Content: "Call mom"},
+		{ID: "7", Content: "Orphan", ParentID: "99"},
+	}
+
+	got := []string{}
+	for _, root := range TaskTree(tasks) {
+		root.Walk(func(node *TaskNode, depth int) {
+			got = append(got, fmt.Sprintf("%d:%s", depth, node.Content))
+		})
+	}
+
+	want := []string{"0:Write report", "1:Collect data", "2:Charts", "1:Outline", "0:Call mom", "0:Orphan"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Unexpected task tree %v, want %v", got, want)
+	}
+}
+
+func TestSubtask(t *testing.T) {
+	c, s := newTestClient(t)
+	project := s.AddProject("Work")
+	parent := s.AddTask(todoisttest.Task{Content: "write report", ProjectID: project.ID})
+	task := s.AddTask(todoisttest.Task{Content: "collect data"})
+
+	subtask, err := c.AddTaskWithParams(&AddTaskParams{Content: "draw charts", ParentID: ID(parent.ID)})
+	if err != nil {
+		t.Fatalf("Failed to add a subtask: %s", err)
+	}
+	if subtask.ParentID != ID(parent.ID) || subtask.ProjectID != ID(project.ID) {
+		t.Fatalf("Failed to add a subtask: got %+v", subtask)
+	}
+
+	if err := c.MoveTaskWithParams(ID(task.ID), &MoveTaskParams{ParentID: ID(parent.ID)}); err != nil {
+		t.Fatalf("Failed to indent the task: %s", err)
+	}
+	if err := c.MoveTaskWithParams(ID(parent.ID), &MoveTaskParams{ParentID: subtask.ID}); err == nil {
+		t.Fatalf("Expected an error when moving a task under its own subtask")
+	}
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if synced, _ := e.Task(ID(task.ID)); synced.ParentID != ID(parent.ID) || synced.ProjectID != ID(project.ID) {
+		t.Fatalf("Expected the synced task under its parent, got %+v", synced)
+	}
+
+	if err := c.MoveTaskWithParams(ID(task.ID), &MoveTaskParams{ProjectID: ID(project.ID)}); err != nil {
+		t.Fatalf("Failed to outdent the task: %s", err)
+	}
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	if synced, _ := e.Task(ID(task.ID)); synced.ParentID != "" {
+		t.Fatalf("Expected the synced task at the top level, got %+v", synced)
+	}
+
+	if err := c.CloseTask(ID(parent.ID)); err != nil {
+		t.Fatalf("Failed to complete the task: %s", err)
+	}
+	if closed, _ := s.Task(todoisttest.ID(subtask.ID)); !closed.Completed {
+		t.Fatalf("Expected the subtask to be completed with its parent, got %+v", closed)
+	}
+}
+
+func TestTaskDetails(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	item, err := c.AddTaskWithParams(&AddTaskParams{
+		Content:      "report",
+		Description:  "Quarterly numbers\nfor the board",
+		Duration:     &Duration{Amount: 90, Unit: DurationMinute},
+		DeadlineDate: "2030-01-31",
+		DueParams:    DueParams{DueDate: "2030-01-20"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create a task: %s", err)
+	}
+
+	item, err = c.GetTask(item.ID)
+	if err != nil {
+		t.Fatalf("Failed to get the task: %s", err)
+	}
+	if item.Description != "Quarterly numbers\nfor the board" || item.Duration == nil || item.Duration.String() != "90 minutes" {
+		t.Fatalf("Unexpected description or duration %+v", item)
+	}
+	if item.Deadline == nil || item.Deadline.Date != "2030-01-31" {
+		t.Fatalf("Unexpected deadline %+v", item.Deadline)
+	}
+	if item.CreatedTime().IsZero() || item.CreatorID != ID(todoisttest.UserID) {
+		t.Fatalf("Unexpected creation %q by %q", item.CreatedAt, item.CreatorID)
+	}
+
+	e := NewSyncEngine(c)
+	if err := e.Sync(); err != nil {
+		t.Fatalf("Failed to sync: %s", err)
+	}
+	synced, _ := e.Task(item.ID)
+	if synced.Description != item.Description || synced.Duration == nil || synced.Deadline == nil || synced.CreatedAt == "" || synced.CreatorID != item.CreatorID {
+		t.Fatalf("Expected the synced task to keep the details, got %+v", synced)
+	}
+
+	empty := ""
+	if err := c.UpdateTaskWithParams(item.ID, &UpdateTaskParams{
+		Description:  &empty,
+		Duration:     &Duration{},
+		DeadlineDate: &empty,
+	}); err != nil {
+		t.Fatalf("Failed to update the task: %s", err)
+	}
+	item, err = c.GetTask(item.ID)
+	if err != nil {
+		t.Fatalf("Failed to get the task: %s", err)
+	}
+	if item.Description != "" || item.Duration != nil || item.Deadline != nil {
+		t.Fatalf("Expected the details to be removed, got %+v", item)
+	}
+}
+
+func TestDurationString(t *testing.T) {
+	tests := []struct {
+		duration Duration
+		want     string
+	}{
+		{Duration{Amount: 1, Unit: DurationMinute}, "1 minute"},
+		{Duration{Amount: 45, Unit: DurationMinute}, "45 minutes"},
+		{Duration{Amount: 120, Unit: DurationMinute}, "2 hours"},
+		{Duration{Amount: 1, Unit: DurationDay}, "1 day"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.duration.String(); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestClearDue(t *testing.T) {
+	c, s := newTestClient(t)
+	item := s.AddTask(todoisttest.Task{Content: "due", Due: &todoisttest.Due{Date: "2021-06-02", String: "Jun 2"}})
+
+	if err := c.UpdateTaskWithParams(ID(item.ID), dueUpdate("")); err != nil {
+		t.Fatalf("Failed to clear the due date: %s", err)
+	}
+	task, err := c.GetTask(ID(item.ID))
+	if err != nil {
+		t.Fatalf("Failed to get the task: %s", err)
+	}
+	if !task.Due.IsZero() {
+		t.Fatalf("Expected the due date to be cleared, got %+v", task.Due)
+	}
+
+	if err := c.UpdateTaskWithParams(ID(item.ID), dueUpdate("tomorrow")); err != nil {
+		t.Fatalf("Failed to set the due date: %s", err)
+	}
+	if task, err = c.GetTask(ID(item.ID)); err != nil || task.Due.String != "tomorrow" {
+		t.Fatalf("Expected the due date to be set, got %v, %+v", err, task)
+	}
+}
+
+func TestUnifiedTaskDetails(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	item := s.AddTask(todoisttest.Task{Content: "detailed"})
+
+	description := "details"
+	if err := c.UpdateTaskWithParams(ID(item.ID), &UpdateTaskParams{Description: &description, Duration: &Duration{Amount: 1, Unit: DurationDay}}); err != nil {
+		t.Fatalf("Failed to update the details: %s", err)
+	}
+	if task, err := c.GetTask(ID(item.ID)); err != nil || task.Description != description || task.Duration == nil || task.CreatedAt == "" || task.CreatorID == "" {
+		t.Fatalf("Expected the task details, got %v, %+v", err, task)
+	}
+}
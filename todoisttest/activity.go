@@ -0,0 +1,127 @@
+package todoisttest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is an entry of the activity log.
+type Event struct {
+	ID              ID                     `json:"id"`
+	ObjectType      string                 `json:"object_type"`
+	ObjectID        ID                     `json:"object_id"`
+	EventType       string                 `json:"event_type"`
+	EventDate       string                 `json:"event_date"`
+	ParentProjectID ID                     `json:"parent_project_id"`
+	ParentItemID    ID                     `json:"parent_item_id"`
+	InitiatorID     ID                     `json:"initiator_id"`
+	ExtraData       map[string]interface{} `json:"extra_data"`
+}
+
+func (s *Server) logTask(t *Task, eventType string, extra map[string]interface{}) {
+	if extra == nil {
+		extra = map[string]interface{}{}
+	}
+	extra["content"] = t.Content
+	s.logEvent(&Event{ObjectType: "item", ObjectID: t.ID, EventType: eventType,
+		ParentProjectID: t.ProjectID, ParentItemID: t.ParentID, ExtraData: extra})
+}
+
+func (s *Server) logComment(c *Comment, eventType string) {
+	e := &Event{ObjectType: "note", ObjectID: c.ID, EventType: eventType,
+		ParentProjectID: c.ProjectID, ParentItemID: c.TaskID,
+		ExtraData: map[string]interface{}{"content": c.Content}}
+	if t, ok := s.tasks[c.TaskID]; ok {
+		e.ParentProjectID = t.ProjectID
+	}
+	s.logEvent(e)
+}
+
+func (s *Server) logProject(p *Project, eventType string, extra map[string]interface{}) {
+	if extra == nil {
+		extra = map[string]interface{}{}
+	}
+	extra["name"] = p.Name
+	s.logEvent(&Event{ObjectType: "project", ObjectID: p.ID, EventType: eventType,
+		ParentProjectID: p.ParentID, ExtraData: extra})
+}
+
+func (s *Server) logEvent(e *Event) {
+	e.ID = ID(strconv.Itoa(len(s.events) + 1))
+	e.EventDate = time.Now().UTC().Format(time.RFC3339)
+	e.InitiatorID = UserID
+	s.events = append(s.events, e)
+}
+
+// activity returns the events matching the filter parameters, newest first.
+func (s *Server) activity(q url.Values) []interface{} {
+	types := q["object_event_types"]
+	if len(types) == 1 && strings.HasPrefix(types[0], "[") {
+		types = strings.Split(strings.Trim(types[0], `[]`), ",")
+	}
+
+	list := []interface{}{}
+	for i := len(s.events) - 1; i >= 0; i-- {
+		e := s.events[i]
+		switch {
+		case q.Get("object_type") != "" && e.ObjectType != q.Get("object_type"):
+		case q.Get("object_id") != "" && e.ObjectID != ID(q.Get("object_id")):
+		case q.Get("event_type") != "" && e.EventType != q.Get("event_type"):
+		case q.Get("parent_project_id") != "" && e.ParentProjectID != ID(q.Get("parent_project_id")):
+		case q.Get("parent_item_id") != "" && e.ParentItemID != ID(q.Get("parent_item_id")):
+		case q.Get("initiator_id") != "" && e.InitiatorID != ID(q.Get("initiator_id")):
+		case len(types) > 0 && !matchEventType(types, e):
+		default:
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// matchEventType matches object_event_types entries such as "item:",
+// ":deleted" and "note:added".
+func matchEventType(types []string, e *Event) bool {
+	for _, v := range types {
+		v = strings.Trim(v, ` "`)
+		i := strings.Index(v, ":")
+		if i < 0 {
+			continue
+		}
+		if (v[:i] == "" || v[:i] == e.ObjectType) && (v[i+1:] == "" || v[i+1:] == e.EventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveActivity answers /activity/get, which pages by offset and returns at
+// most 100 events at a time together with the total count.
+func (s *Server) serveActivity(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list := s.activity(r.Form)
+	count := len(list)
+
+	limit, _ := strconv.Atoi(r.Form.Get("limit"))
+	if limit <= 0 {
+		limit = 30
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, _ := strconv.Atoi(r.Form.Get("offset"))
+	if offset > len(list) {
+		offset = len(list)
+	}
+	if offset+limit < len(list) {
+		list = list[:offset+limit]
+	}
+
+	writeJSON(w, map[string]interface{}{"events": list[offset:], "count": count})
+}
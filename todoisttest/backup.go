@@ -0,0 +1,41 @@
+package todoisttest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Backup is a backup of the account.
+type Backup struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+}
+
+// backupToken is the token the download URLs of backups carry.
+const backupToken = "backup-token"
+
+// AddBackup seeds a backup made at version, e.g. "2019-05-01 02:03", whose
+// archive is data, and returns a copy of it.
+func (s *Server) AddBackup(version string, data []byte) Backup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := fmt.Sprintf("%s/backups/%s.zip", filesPrefix, s.newID())
+	s.files[p] = data
+
+	b := &Backup{Version: version, URL: s.URL + p + "?token=" + backupToken}
+	s.backups = append(s.backups, b)
+	return *b
+}
+
+// serveBackups lists the backups in the order they were added.
+func (s *Server) serveBackups(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.backups)
+}
+
+// isBackup reports whether the file is a backup archive, which can only be
+// downloaded with the token of its URL.
+func isBackup(path string) bool {
+	return strings.HasPrefix(path, filesPrefix+"/backups/")
+}
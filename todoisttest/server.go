@@ -0,0 +1,2257 @@
+// Package todoisttest provides an in-process fake of the Todoist REST and
+// Sync APIs for hermetic tests.
+//
+// A Server keeps tasks, projects, labels and comments in memory and serves
+// them the way the real service does, so a todoist.Client pointed at
+// RESTURL and SyncURL behaves as it would against api.todoist.com.
+package todoisttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	restPrefix = "/rest/v1"
+	syncPrefix = "/sync/v8"
+	// filesPrefix serves uploaded files.
+	filesPrefix = "/files"
+)
+
+type Due struct {
+	Date      string `json:"date,omitempty"`
+	Datetime  string `json:"datetime,omitempty"`
+	Recurring bool   `json:"recurring,omitempty"`
+	String    string `json:"string,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+}
+
+// ID identifies a fake object. IDs are numeric strings, encoded as JSON
+// numbers on the v1 REST and v8 Sync endpoints like the real service does.
+type ID string
+
+func (id ID) MarshalJSON() ([]byte, error) {
+	if id == "" {
+		return []byte("null"), nil
+	}
+	return []byte(id), nil
+}
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	*id = ID(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// record tracks the version at which an object last changed, so that /sync
+// can answer incremental requests.
+type record struct {
+	version int
+	deleted bool
+}
+
+type Task struct {
+	record
+
+	ID           ID        `json:"id"`
+	Content      string    `json:"content"`
+	Description  string    `json:"description"`
+	ProjectID    ID        `json:"project_id"`
+	SectionID    ID        `json:"section_id,omitempty"`
+	ParentID     ID        `json:"parent_id,omitempty"`
+	LabelIDs     []ID      `json:"label_ids"`
+	Priority     uint      `json:"priority"`
+	Completed    bool      `json:"completed"`
+	CommentCount uint      `json:"comment_count"`
+	Order        uint      `json:"order"`
+	URL          string    `json:"url"`
+	Due          *Due      `json:"due,omitempty"`
+	AssigneeID   ID        `json:"assignee,omitempty"`
+	AssignerID   ID        `json:"assigner,omitempty"`
+	Duration     *Duration `json:"duration,omitempty"`
+	Deadline     *Deadline `json:"deadline,omitempty"`
+	Created      string    `json:"created"`
+	CreatorID    ID        `json:"creator"`
+
+	completedAt time.Time
+}
+
+type Duration struct {
+	Amount int    `json:"amount"`
+	Unit   string `json:"unit"`
+}
+
+type Deadline struct {
+	Date string `json:"date"`
+}
+
+type Project struct {
+	record
+
+	ID           ID     `json:"id"`
+	ParentID     ID     `json:"parent_id,omitempty"`
+	Name         string `json:"name"`
+	Color        uint   `json:"color"`
+	Order        uint   `json:"order"`
+	CommentCount uint   `json:"comment_count"`
+	Favorite     bool   `json:"favorite"`
+	InboxProject bool   `json:"inbox_project"`
+	Archived     bool   `json:"is_archived"`
+}
+
+type Section struct {
+	record
+
+	ID        ID     `json:"id"`
+	ProjectID ID     `json:"project_id"`
+	Name      string `json:"name"`
+	Order     uint   `json:"order"`
+}
+
+type Label struct {
+	record
+
+	ID       ID     `json:"id"`
+	Name     string `json:"name"`
+	Color    uint   `json:"color"`
+	Order    uint   `json:"order"`
+	Favorite bool   `json:"favorite"`
+}
+
+// Filter is a saved filter query.
+type Filter struct {
+	record
+
+	ID       ID     `json:"id"`
+	Name     string `json:"name"`
+	Query    string `json:"query"`
+	Color    uint   `json:"color"`
+	Order    uint   `json:"item_order"`
+	Favorite bool   `json:"is_favorite"`
+}
+
+type Comment struct {
+	record
+
+	ID        ID     `json:"id"`
+	TaskID    ID     `json:"task_id,omitempty"`
+	ProjectID ID     `json:"project_id,omitempty"`
+	Posted    string `json:"posted"`
+	// PosterID is left out of REST v1 comments like the real service does,
+	// and only reported by the Sync and unified APIs.
+	PosterID ID     `json:"-"`
+	Content  string `json:"content"`
+
+	Attachment *Attachment `json:"attachment,omitempty"`
+}
+
+// Collaborator is a user projects can be shared with.
+type Collaborator struct {
+	ID    ID     `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type Reminder struct {
+	record
+
+	ID           ID     `json:"id"`
+	TaskID       ID     `json:"item_id"`
+	Type         string `json:"type"`
+	MinuteOffset int    `json:"minute_offset,omitempty"`
+	Due          *Due   `json:"due,omitempty"`
+}
+
+type Attachment struct {
+	FileName     string `json:"file_name"`
+	FileType     string `json:"file_type"`
+	FileSize     int    `json:"file_size"`
+	FileURL      string `json:"file_url"`
+	ResourceType string `json:"resource_type"`
+	UploadState  string `json:"upload_state"`
+}
+
+// Failure describes a canned error response returned instead of the
+// regular handler for matching requests.
+type Failure struct {
+	// Method restricts the failure to one HTTP method. Empty matches any.
+	Method string
+	// Path is matched as a prefix of the request path, e.g. "/rest/v1/tasks".
+	// Empty matches any path.
+	Path string
+
+	StatusCode int
+	Body       string
+	Header     http.Header
+
+	// Times is the number of requests to fail. Zero fails every request.
+	Times int
+}
+
+// Request is a summary of a request received by the Server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+}
+
+// UserID is the ID of the user owning the fake account.
+const UserID ID = "1"
+
+// Server is a fake Todoist service backed by in-memory state.
+type Server struct {
+	*httptest.Server
+
+	// Token, when non-empty, is the only bearer token accepted.
+	Token string
+	// Premium is reported as the user's premium status by /sync.
+	Premium bool
+	// PageSize is the maximum number of objects in a page of the unified
+	// API. Zero uses the default of 50.
+	PageSize int
+	// Timezone is the user's timezone reported by /sync. Empty reports UTC.
+	Timezone string
+	// DailyGoal and WeeklyGoal are the goals reported by the productivity
+	// stats. Zero uses 5 and 25.
+	DailyGoal  int
+	WeeklyGoal int
+	// Hook, when set, is called before every request is handled. Returning
+	// true means the hook wrote the response itself.
+	Hook func(w http.ResponseWriter, r *http.Request) bool
+
+	mu        sync.Mutex
+	nextID    uint
+	version   int
+	inboxID   ID
+	tasks     map[ID]*Task
+	projects  map[ID]*Project
+	sections  map[ID]*Section
+	labels    map[ID]*Label
+	comments  map[ID]*Comment
+	reminders map[ID]*Reminder
+	filters   map[ID]*Filter
+	// users holds every collaborator and shares the users each project is
+	// shared with besides its owner.
+	users    map[ID]*Collaborator
+	shares   map[ID][]ID
+	files    map[string][]byte
+	backups  []*Backup
+	events   []*Event
+	failures []*Failure
+	requests []Request
+}
+
+// NewServer starts a fake server holding a single Inbox project.
+func NewServer() *Server {
+	s := &Server{
+		nextID:    1000,
+		tasks:     map[ID]*Task{},
+		projects:  map[ID]*Project{},
+		sections:  map[ID]*Section{},
+		labels:    map[ID]*Label{},
+		comments:  map[ID]*Comment{},
+		reminders: map[ID]*Reminder{},
+		filters:   map[ID]*Filter{},
+		users:     map[ID]*Collaborator{UserID: {ID: UserID, Name: "Test User", Email: "test@example.com"}},
+		shares:    map[ID][]ID{},
+		files:     map[string][]byte{},
+		backups:   []*Backup{},
+	}
+	s.inboxID = s.AddProject("Inbox").ID
+	s.projects[s.inboxID].InboxProject = true
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// RESTURL returns the base URL to pass to todoist.WithRESTBaseURL.
+func (s *Server) RESTURL() string {
+	return s.URL + restPrefix
+}
+
+// SyncURL returns the base URL to pass to todoist.WithSyncBaseURL.
+func (s *Server) SyncURL() string {
+	return s.URL + syncPrefix
+}
+
+// InjectFailure makes matching requests fail with the given response.
+func (s *Server) InjectFailure(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, &f)
+}
+
+// Requests returns every request received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// AddProject seeds a project and returns a copy of it.
+func (s *Server) AddProject(name string) Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.insertProject(&Project{Name: name})
+}
+
+// Project returns a copy of the project with the given ID, archived or not.
+func (s *Server) Project(id ID) (Project, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.project(id)
+	if !ok {
+		return Project{}, false
+	}
+	return *p, true
+}
+
+// AddSection seeds a section in a project and returns a copy of it.
+func (s *Server) AddSection(projectID ID, name string) Section {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.insertSection(&Section{ProjectID: projectID, Name: name})
+}
+
+// AddLabel seeds a label and returns a copy of it.
+func (s *Server) AddLabel(name string) Label {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.insertLabel(&Label{Name: name})
+}
+
+// AddFilter seeds a saved filter and returns a copy of it.
+func (s *Server) AddFilter(name, query string) Filter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.insertFilter(&Filter{Name: name, Query: query})
+}
+
+// AddTask seeds a task and returns a copy of it. A zero ProjectID places the
+// task in the Inbox.
+func (s *Server) AddTask(t Task) Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.insertTask(&t)
+}
+
+// CompleteTask completes a task and its subtasks as if it was done at the
+// given time by another client.
+func (s *Server) CompleteTask(id ID, at time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.task(id)
+	if ok {
+		s.completeTask(t, true)
+		for _, v := range s.subtasks(t) {
+			v.completedAt = at.UTC()
+		}
+	}
+	return ok
+}
+
+// AddCollaborator shares a project with a new user and returns the user.
+func (s *Server) AddCollaborator(projectID ID, name, email string) Collaborator {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &Collaborator{ID: s.newID(), Name: name, Email: email}
+	s.users[c.ID] = c
+	s.shares[projectID] = append(s.shares[projectID], c.ID)
+	return *c
+}
+
+// AddComment seeds a comment and returns a copy of it.
+func (s *Server) AddComment(c Comment) Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.insertComment(&c)
+}
+
+// Task returns a copy of the task with the given ID.
+func (s *Server) Task(id ID) (Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.task(id)
+	if !ok {
+		return Task{}, false
+	}
+	return *t, true
+}
+
+// Tasks returns copies of every task, completed or not, ordered by ID.
+func (s *Server) Tasks() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []Task{}
+	for _, id := range sortedIDs(s.tasks) {
+		if t := s.tasks[id]; !t.deleted {
+			list = append(list, *t)
+		}
+	}
+	return list
+}
+
+// DeleteTask removes a task as if it was deleted by another client.
+func (s *Server) DeleteTask(id ID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.task(id)
+	if ok {
+		s.deleteTask(t)
+	}
+	return ok
+}
+
+// UpdateTask modifies a task as if it was edited by another client.
+func (s *Server) UpdateTask(id ID, update func(*Task)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.task(id)
+	if ok {
+		update(t)
+		s.touch(&t.record)
+	}
+	return ok
+}
+
+func (s *Server) touch(r *record) {
+	s.version++
+	r.version = s.version
+}
+
+func (s *Server) task(id ID) (*Task, bool) {
+	t, ok := s.tasks[id]
+	if !ok || t.deleted {
+		return nil, false
+	}
+	return t, true
+}
+
+func (s *Server) project(id ID) (*Project, bool) {
+	p, ok := s.projects[id]
+	if !ok || p.deleted {
+		return nil, false
+	}
+	return p, true
+}
+
+func (s *Server) newID() ID {
+	s.nextID++
+	return ID(strconv.FormatUint(uint64(s.nextID), 10))
+}
+
+func (s *Server) insertTask(t *Task) *Task {
+	t.ID = s.newID()
+	if t.ProjectID == "" {
+		t.ProjectID = s.inboxID
+	}
+	if t.Priority == 0 {
+		t.Priority = 1
+	}
+	if t.LabelIDs == nil {
+		t.LabelIDs = []ID{}
+	}
+	t.Order = uint(len(s.tasks) + 1)
+	t.URL = fmt.Sprintf("https://todoist.com/showTask?id=%s", t.ID)
+	if t.Created == "" {
+		t.Created = time.Now().UTC().Format("2006-01-02T15:04:05.000000Z")
+	}
+	if t.CreatorID == "" {
+		t.CreatorID = UserID
+	}
+
+	s.touch(&t.record)
+	s.tasks[t.ID] = t
+	s.logTask(t, "added", nil)
+	return t
+}
+
+func (s *Server) deleteComment(c *Comment) {
+	if t, ok := s.task(c.TaskID); ok && t.CommentCount > 0 {
+		t.CommentCount--
+		s.touch(&t.record)
+	}
+	if p, ok := s.project(c.ProjectID); ok && p.CommentCount > 0 {
+		p.CommentCount--
+		s.touch(&p.record)
+	}
+
+	c.deleted = true
+	s.touch(&c.record)
+	s.logComment(c, "deleted")
+}
+
+func (s *Server) insertProject(p *Project) *Project {
+	p.ID = s.newID()
+	p.Order = uint(len(s.projects) + 1)
+
+	s.touch(&p.record)
+	s.projects[p.ID] = p
+	s.logProject(p, "added", nil)
+	return p
+}
+
+func (s *Server) section(id ID) (*Section, bool) {
+	v, ok := s.sections[id]
+	if !ok || v.deleted {
+		return nil, false
+	}
+	return v, true
+}
+
+func (s *Server) insertSection(v *Section) *Section {
+	v.ID = s.newID()
+	for _, other := range s.sections {
+		if other.ProjectID == v.ProjectID && other.Order >= v.Order {
+			v.Order = other.Order + 1
+		}
+	}
+	if v.Order == 0 {
+		v.Order = 1
+	}
+
+	s.touch(&v.record)
+	s.sections[v.ID] = v
+	return v
+}
+
+// deleteSection deletes the section with its tasks.
+func (s *Server) deleteSection(v *Section) {
+	for _, t := range s.tasks {
+		if t.SectionID == v.ID && !t.deleted {
+			t.deleted = true
+			s.touch(&t.record)
+		}
+	}
+	v.deleted = true
+	s.touch(&v.record)
+}
+
+func (s *Server) insertLabel(l *Label) *Label {
+	l.ID = s.newID()
+	l.Order = uint(len(s.labels) + 1)
+
+	s.touch(&l.record)
+	s.labels[l.ID] = l
+	return l
+}
+
+func (s *Server) insertFilter(f *Filter) *Filter {
+	f.ID = s.newID()
+	f.Order = uint(len(s.filters) + 1)
+
+	s.touch(&f.record)
+	s.filters[f.ID] = f
+	return f
+}
+
+// deleteLabel deletes the label and removes it from every task.
+func (s *Server) deleteLabel(l *Label) {
+	for _, t := range s.tasks {
+		if !t.deleted && containsID(t.LabelIDs, l.ID) {
+			ids := []ID{}
+			for _, id := range t.LabelIDs {
+				if id != l.ID {
+					ids = append(ids, id)
+				}
+			}
+			t.LabelIDs = ids
+			s.touch(&t.record)
+		}
+	}
+	l.deleted = true
+	s.touch(&l.record)
+}
+
+func (s *Server) label(id ID) (*Label, bool) {
+	l, ok := s.labels[id]
+	if !ok || l.deleted {
+		return nil, false
+	}
+	return l, true
+}
+
+// subprojects returns the project and all its descendants.
+func (s *Server) subprojects(p *Project) []*Project {
+	list := []*Project{p}
+	for _, id := range sortedIDs(s.projects) {
+		if child := s.projects[id]; !child.deleted && child.ParentID == p.ID {
+			list = append(list, s.subprojects(child)...)
+		}
+	}
+	return list
+}
+
+// deleteProject deletes the project with its descendants and their tasks.
+func (s *Server) deleteProject(p *Project) {
+	for _, v := range s.subprojects(p) {
+		for _, section := range s.sections {
+			if section.ProjectID == v.ID && !section.deleted {
+				section.deleted = true
+				s.touch(&section.record)
+			}
+		}
+		for _, t := range s.tasks {
+			if t.ProjectID == v.ID && !t.deleted {
+				t.deleted = true
+				s.touch(&t.record)
+			}
+		}
+		v.deleted = true
+		s.touch(&v.record)
+		s.logProject(v, "deleted", nil)
+	}
+}
+
+func (s *Server) archiveProject(p *Project, archived bool) {
+	eventType := "unarchived"
+	if archived {
+		eventType = "archived"
+	}
+	for _, v := range s.subprojects(p) {
+		v.Archived = archived
+		s.touch(&v.record)
+		s.logProject(v, eventType, nil)
+	}
+}
+
+// collaborators returns the owner and the users the project is shared with.
+func (s *Server) collaborators(projectID ID) []*Collaborator {
+	list := []*Collaborator{s.users[UserID]}
+	for _, id := range s.shares[projectID] {
+		list = append(list, s.users[id])
+	}
+	return list
+}
+
+func (s *Server) isCollaborator(projectID, userID ID) bool {
+	for _, c := range s.collaborators(projectID) {
+		if c.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// subtasks returns the task and all its descendants.
+func (s *Server) subtasks(t *Task) []*Task {
+	list := []*Task{t}
+	for _, id := range sortedIDs(s.tasks) {
+		if child := s.tasks[id]; !child.deleted && child.ParentID == t.ID {
+			list = append(list, s.subtasks(child)...)
+		}
+	}
+	return list
+}
+
+// completeTask completes or reopens the task. Completing a task completes
+// its subtasks too, and reopening a subtask reopens its parents.
+func (s *Server) completeTask(t *Task, completed bool) {
+	list := s.subtasks(t)
+	if !completed {
+		list = []*Task{t}
+		for p, ok := s.task(t.ParentID); ok; p, ok = s.task(p.ParentID) {
+			list = append(list, p)
+		}
+	}
+
+	for _, v := range list {
+		v.Completed = completed
+		v.completedAt = time.Now().UTC()
+		s.touch(&v.record)
+		if completed {
+			s.logTask(v, "completed", nil)
+		} else {
+			s.logTask(v, "uncompleted", nil)
+		}
+	}
+}
+
+func (s *Server) deleteTask(t *Task) {
+	for _, v := range s.subtasks(t) {
+		v.deleted = true
+		s.touch(&v.record)
+		s.logTask(v, "deleted", nil)
+
+		for _, r := range s.reminders {
+			if r.TaskID == v.ID && !r.deleted {
+				r.deleted = true
+				s.touch(&r.record)
+			}
+		}
+	}
+}
+
+func (s *Server) insertComment(c *Comment) *Comment {
+	c.ID = s.newID()
+	if c.Posted == "" {
+		c.Posted = "2019-01-01T00:00:00Z"
+	}
+	if c.PosterID == "" {
+		c.PosterID = UserID
+	}
+	if t, ok := s.task(c.TaskID); ok {
+		t.CommentCount++
+		s.touch(&t.record)
+	}
+	if p, ok := s.project(c.ProjectID); ok {
+		p.CommentCount++
+		s.touch(&p.record)
+	}
+
+	s.touch(&c.record)
+	s.comments[c.ID] = c
+	s.logComment(c, "added")
+	return c
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone()})
+	hook := s.Hook
+	s.mu.Unlock()
+
+	if hook != nil && hook(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		http.Error(w, "Forbidden", http.StatusUnauthorized)
+		return
+	}
+
+	if f := s.matchFailure(r); f != nil {
+		for k, v := range f.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(f.StatusCode)
+		fmt.Fprint(w, f.Body)
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, unifiedPrefix+"/"):
+		s.serveUnified(w, r)
+	case strings.HasPrefix(r.URL.Path, restPrefix+"/"):
+		s.serveREST(w, r, splitPath(strings.TrimPrefix(r.URL.Path, restPrefix)))
+	case r.URL.Path == syncPrefix+"/sync" && r.Method == "POST":
+		s.serveSync(w, r)
+	case r.URL.Path == syncPrefix+"/quick/add" && r.Method == "POST":
+		s.serveQuickAdd(w, r)
+	case r.URL.Path == syncPrefix+"/activity/get":
+		s.serveActivity(w, r)
+	case r.URL.Path == syncPrefix+"/completed/get_all" && r.Method == "GET":
+		s.serveCompleted(w, r)
+	case r.URL.Path == syncPrefix+"/backups/get" && r.Method == "GET":
+		s.serveBackups(w, r)
+	case r.URL.Path == syncPrefix+"/completed/get_stats" && r.Method == "GET":
+		s.serveStats(w, r)
+	case r.URL.Path == syncPrefix+"/uploads/add" && r.Method == "POST":
+		s.serveUpload(w, r)
+	case strings.HasPrefix(r.URL.Path, filesPrefix+"/") && r.Method == "GET":
+		s.serveFile(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) matchFailure(r *http.Request) *Failure {
+	for i, f := range s.failures {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) serveREST(w http.ResponseWriter, r *http.Request, elm []string) {
+	switch {
+	case len(elm) == 1 && elm[0] == "tasks" && r.Method == "GET":
+		s.listTasks(w, r)
+	case len(elm) == 1 && elm[0] == "tasks" && r.Method == "POST":
+		s.createTask(w, r)
+	case len(elm) == 2 && elm[0] == "tasks":
+		s.serveTask(w, r, elm[1])
+	case len(elm) == 3 && elm[0] == "tasks" && r.Method == "POST":
+		s.serveTaskAction(w, r, elm[1], elm[2])
+	case len(elm) == 1 && elm[0] == "projects" && r.Method == "GET":
+		list := []*Project{}
+		for _, id := range sortedIDs(s.projects) {
+			if p := s.projects[id]; !p.deleted && !p.Archived {
+				list = append(list, p)
+			}
+		}
+		writeJSON(w, list)
+	case len(elm) == 1 && elm[0] == "projects" && r.Method == "POST":
+		s.createProject(w, r)
+	case len(elm) == 2 && elm[0] == "projects":
+		s.serveProject(w, r, elm[1])
+	case len(elm) == 3 && elm[0] == "projects" && elm[2] == "collaborators" && r.Method == "GET":
+		id, _ := parseID(elm[1])
+		if _, ok := s.project(id); !ok {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, s.collaborators(id))
+	case len(elm) == 1 && elm[0] == "labels" && r.Method == "GET":
+		list := []*Label{}
+		for _, id := range sortedIDs(s.labels) {
+			if l := s.labels[id]; !l.deleted {
+				list = append(list, l)
+			}
+		}
+		writeJSON(w, list)
+	case len(elm) == 1 && elm[0] == "sections" && r.Method == "GET":
+		s.listSections(w, r)
+	case len(elm) == 1 && elm[0] == "sections" && r.Method == "POST":
+		s.createSection(w, r)
+	case len(elm) == 2 && elm[0] == "sections":
+		s.serveSection(w, r, elm[1])
+	case len(elm) == 1 && elm[0] == "labels" && r.Method == "POST":
+		s.createLabel(w, r)
+	case len(elm) == 2 && elm[0] == "labels":
+		s.serveLabel(w, r, elm[1])
+	case len(elm) == 1 && elm[0] == "comments" && r.Method == "GET":
+		s.listComments(w, r)
+	case len(elm) == 1 && elm[0] == "comments" && r.Method == "POST":
+		s.createComment(w, r)
+	case len(elm) == 2 && elm[0] == "comments":
+		s.serveComment(w, r, elm[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var projectID, labelID ID
+	var ok bool
+	if v := q.Get("project_id"); v != "" {
+		if projectID, ok = parseID(v); !ok {
+			http.Error(w, "Invalid argument value", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("label_id"); v != "" {
+		if labelID, ok = parseID(v); !ok {
+			http.Error(w, "Invalid argument value", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("filter"); v != "" {
+		switch {
+		case strings.HasPrefix(v, "#"):
+			if projectID = s.projectByName(v[1:]); projectID == "" {
+				writeJSON(w, []*Task{})
+				return
+			}
+		case strings.HasPrefix(v, "@"):
+			if labelID = s.labelByName(v[1:]); labelID == "" {
+				writeJSON(w, []*Task{})
+				return
+			}
+		default:
+			http.Error(w, fmt.Sprintf("Unsupported filter: %s", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var ids []ID
+	if v := q.Get("ids"); v != "" {
+		for _, elm := range strings.Split(v, ",") {
+			id, ok := parseID(elm)
+			if !ok {
+				http.Error(w, "Invalid argument value", http.StatusBadRequest)
+				return
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	list := []*Task{}
+	for _, id := range sortedIDs(s.tasks) {
+		t := s.tasks[id]
+		if ids != nil && !containsID(ids, id) {
+			continue
+		}
+		if t.deleted || t.Completed {
+			continue
+		}
+		if projectID != "" && t.ProjectID != projectID {
+			continue
+		}
+		if labelID != "" && !containsID(t.LabelIDs, labelID) {
+			continue
+		}
+		list = append(list, t)
+	}
+	writeJSON(w, list)
+}
+
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	args := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if content, _ := args["content"].(string); content == "" {
+		http.Error(w, "Required argument is missing", http.StatusBadRequest)
+		return
+	}
+
+	t := &Task{}
+	if err := s.applyTaskArgs(t, args, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, s.insertTask(t))
+}
+
+func (s *Server) serveTask(w http.ResponseWriter, r *http.Request, idString string) {
+	id, _ := parseID(idString)
+	t, ok := s.task(id)
+	if !ok {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, t)
+	case "POST":
+		args := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.applyTaskArgs(t, args, nil); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.touch(&t.record)
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE":
+		s.deleteTask(t)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) serveTaskAction(w http.ResponseWriter, r *http.Request, idString, action string) {
+	id, _ := parseID(idString)
+	t, ok := s.task(id)
+	if !ok {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	switch action {
+	case "close":
+		s.completeTask(t, true)
+	case "reopen":
+		s.completeTask(t, false)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) applyTaskArgs(t *Task, args map[string]interface{}, tempIDs map[string]ID) error {
+	// A parent, when given, decides the project and section of the task.
+	_, hasParent := args["parent_id"]
+	lastContent := t.Content
+	assigneeID, assignerID := t.AssigneeID, t.AssignerID
+	for k, v := range args {
+		switch k {
+		case "content":
+			t.Content = fmt.Sprint(v)
+		case "description":
+			t.Description = fmt.Sprint(v)
+		case "duration", "duration_unit":
+			if err := applyDuration(t, args); err != nil {
+				return err
+			}
+		case "deadline_date":
+			t.Deadline = nil
+			if v != nil {
+				date := fmt.Sprint(v)
+				if _, err := time.Parse("2006-01-02", date); err != nil {
+					return errInvalidArgument
+				}
+				t.Deadline = &Deadline{Date: date}
+			}
+		case "deadline":
+			deadline, _ := v.(map[string]interface{})
+			t.Deadline = nil
+			if date, _ := deadline["date"].(string); date != "" {
+				t.Deadline = &Deadline{Date: date}
+			}
+		case "project_id":
+			id, _ := s.resolveID(v, tempIDs)
+			if _, ok := s.project(id); !ok {
+				return errInvalidArgument
+			}
+			if hasParent {
+				continue
+			}
+			if t.ProjectID != id {
+				t.SectionID = ""
+			}
+			t.ProjectID = id
+			t.ParentID = ""
+		case "section_id":
+			id, _ := s.resolveID(v, tempIDs)
+			section, ok := s.section(id)
+			if !ok {
+				return errInvalidArgument
+			}
+			if hasParent {
+				continue
+			}
+			t.ProjectID = section.ProjectID
+			t.SectionID = id
+			t.ParentID = ""
+		case "parent_id":
+			id, _ := s.resolveID(v, tempIDs)
+			parent, ok := s.task(id)
+			if !ok || (t.ID != "" && s.isSubtask(parent, t)) {
+				return errInvalidArgument
+			}
+			t.ProjectID = parent.ProjectID
+			t.SectionID = parent.SectionID
+			t.ParentID = id
+		case "label_ids", "labels":
+			list, _ := v.([]interface{})
+			t.LabelIDs = []ID{}
+			for _, elm := range list {
+				id, ok := s.resolveID(elm, tempIDs)
+				if name, isName := elm.(string); !ok && isName {
+					id = s.labelByName(name)
+					ok = id != ""
+				}
+				if !ok {
+					return errInvalidArgument
+				}
+				t.LabelIDs = append(t.LabelIDs, id)
+			}
+		case "priority":
+			p, ok := v.(float64)
+			if !ok || p < 1 || 4 < p {
+				return errInvalidArgument
+			}
+			t.Priority = uint(p)
+		case "due_string":
+			if s := fmt.Sprint(v); s == "no date" || s == "" {
+				t.Due = nil
+			} else {
+				t.Due = &Due{String: s}
+			}
+		case "due_date":
+			t.Due = &Due{Date: fmt.Sprint(v), String: fmt.Sprint(v)}
+		case "due_datetime":
+			datetime := fmt.Sprint(v)
+			if len(datetime) < 10 {
+				return errInvalidArgument
+			}
+			t.Due = &Due{Date: datetime[:10], Datetime: datetime, String: datetime}
+		case "due":
+			due, _ := v.(map[string]interface{})
+			if due == nil {
+				t.Due = nil
+				continue
+			}
+			t.Due = &Due{}
+			t.Due.String, _ = due["string"].(string)
+			t.Due.Date, _ = due["date"].(string)
+		case "assignee", "assignee_id", "responsible_uid":
+			id, _ := toID(v)
+			if id == "0" {
+				id = ""
+			}
+			t.AssigneeID = id
+			t.AssignerID = ""
+			if id != "" {
+				t.AssignerID = UserID
+			}
+		case "due_lang", "order", "child_order":
+		default:
+			return fmt.Errorf("Unknown argument: %s", k)
+		}
+	}
+
+	projectID := t.ProjectID
+	if projectID == "" {
+		projectID = s.inboxID
+	}
+	if t.AssigneeID != "" && !s.isCollaborator(projectID, t.AssigneeID) {
+		t.AssigneeID, t.AssignerID = assigneeID, assignerID
+		return errInvalidArgument
+	}
+
+	if t.ID == "" {
+		// The task is being created and has no subtasks yet.
+		return nil
+	}
+
+	extra := map[string]interface{}{}
+	if t.Content != lastContent {
+		extra["last_content"] = lastContent
+	}
+	s.logTask(t, "updated", extra)
+
+	// Subtasks follow their parent.
+	for _, child := range s.subtasks(t)[1:] {
+		child.ProjectID = t.ProjectID
+		child.SectionID = t.SectionID
+		s.touch(&child.record)
+	}
+	return nil
+}
+
+// applyDuration sets the duration of the task from the duration argument,
+// either an amount together with duration_unit or an object holding both, as
+// the Sync API takes it. A null duration removes it.
+func applyDuration(t *Task, args map[string]interface{}) error {
+	v, ok := args["duration"]
+	if !ok {
+		return errInvalidArgument
+	}
+	if v == nil {
+		t.Duration = nil
+		return nil
+	}
+
+	d := &Duration{}
+	if m, isMap := v.(map[string]interface{}); isMap {
+		v, args = m["amount"], m
+	}
+	amount, _ := v.(float64)
+	d.Amount = int(amount)
+	d.Unit, _ = args["duration_unit"].(string)
+	if u, ok := args["unit"].(string); ok {
+		d.Unit = u
+	}
+	if d.Amount <= 0 || (d.Unit != "minute" && d.Unit != "day") {
+		return errInvalidArgument
+	}
+	t.Duration = d
+	return nil
+}
+
+// isSubtask reports whether t is root or one of its descendants.
+func (s *Server) isSubtask(t, root *Task) bool {
+	for ; t != nil; t, _ = s.task(t.ParentID) {
+		if t.ID == root.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	args := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if name, _ := args["name"].(string); name == "" {
+		http.Error(w, "Required argument is missing", http.StatusBadRequest)
+		return
+	}
+
+	p := &Project{}
+	if err := s.applyProjectArgs(p, args, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, s.insertProject(p))
+}
+
+func (s *Server) serveProject(w http.ResponseWriter, r *http.Request, idString string) {
+	id, _ := parseID(idString)
+	p, ok := s.project(id)
+	if !ok {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, p)
+	case "POST":
+		args := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, ok := args["parent_id"]; ok {
+			http.Error(w, "Unknown argument: parent_id", http.StatusBadRequest)
+			return
+		}
+		if err := s.applyProjectArgs(p, args, nil); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.touch(&p.record)
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE":
+		if p.InboxProject {
+			http.Error(w, "Inbox project cannot be deleted", http.StatusBadRequest)
+			return
+		}
+		s.deleteProject(p)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) applyProjectArgs(p *Project, args map[string]interface{}, tempIDs map[string]ID) error {
+	lastName := p.Name
+	for k, v := range args {
+		switch k {
+		case "name":
+			p.Name = fmt.Sprint(v)
+		case "parent_id":
+			id, _ := s.resolveID(v, tempIDs)
+			if _, ok := s.project(id); !ok || id == p.ID {
+				return errInvalidArgument
+			}
+			p.ParentID = id
+		case "color":
+			c, ok := v.(float64)
+			if !ok || c < 30 || 49 < c {
+				return errInvalidArgument
+			}
+			p.Color = uint(c)
+		case "favorite", "is_favorite":
+			b, ok := v.(bool)
+			if !ok {
+				return errInvalidArgument
+			}
+			p.Favorite = b
+		case "child_order":
+		default:
+			return fmt.Errorf("Unknown argument: %s", k)
+		}
+	}
+
+	if p.ID != "" {
+		extra := map[string]interface{}{}
+		if p.Name != lastName {
+			extra["last_name"] = lastName
+		}
+		s.logProject(p, "updated", extra)
+	}
+	return nil
+}
+
+func (s *Server) listSections(w http.ResponseWriter, r *http.Request) {
+	projectID, hasProject := parseID(r.URL.Query().Get("project_id"))
+
+	list := []*Section{}
+	for _, id := range sortedIDs(s.sections) {
+		v := s.sections[id]
+		if !v.deleted && (!hasProject || v.ProjectID == projectID) {
+			list = append(list, v)
+		}
+	}
+	writeJSON(w, list)
+}
+
+func (s *Server) createSection(w http.ResponseWriter, r *http.Request) {
+	var args struct {
+		Name      string      `json:"name"`
+		ProjectID interface{} `json:"project_id"`
+		Order     uint        `json:"order"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	projectID, _ := toID(args.ProjectID)
+	if args.Name == "" || projectID == "" {
+		http.Error(w, "Required argument is missing", http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.project(projectID); !ok {
+		http.Error(w, "Project not found", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, s.insertSection(&Section{ProjectID: projectID, Name: args.Name, Order: args.Order}))
+}
+
+func (s *Server) serveSection(w http.ResponseWriter, r *http.Request, idString string) {
+	id, _ := parseID(idString)
+	v, ok := s.section(id)
+	if !ok {
+		http.Error(w, "Section not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, v)
+	case "POST":
+		var args struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if args.Name == "" {
+			http.Error(w, "Required argument is missing", http.StatusBadRequest)
+			return
+		}
+		v.Name = args.Name
+		s.touch(&v.record)
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE":
+		s.deleteSection(v)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) createLabel(w http.ResponseWriter, r *http.Request) {
+	args := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	l := &Label{}
+	if err := s.applyLabelArgs(l, args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if l.Name == "" {
+		http.Error(w, "Required argument is missing", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, s.insertLabel(l))
+}
+
+func (s *Server) serveLabel(w http.ResponseWriter, r *http.Request, idString string) {
+	id, _ := parseID(idString)
+	l, ok := s.label(id)
+	if !ok {
+		http.Error(w, "Label not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, l)
+	case "POST":
+		args := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.applyLabelArgs(l, args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.touch(&l.record)
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE":
+		s.deleteLabel(l)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) applyLabelArgs(l *Label, args map[string]interface{}) error {
+	for k, v := range args {
+		switch k {
+		case "name":
+			name := fmt.Sprint(v)
+			if id := s.labelByName(name); id != "" && id != l.ID {
+				return fmt.Errorf("Label already exists")
+			}
+			l.Name = name
+		case "color":
+			c, ok := v.(float64)
+			if !ok || c < 30 || 49 < c {
+				return errInvalidArgument
+			}
+			l.Color = uint(c)
+		case "favorite", "is_favorite":
+			b, ok := v.(bool)
+			if !ok {
+				return errInvalidArgument
+			}
+			l.Favorite = b
+		case "order", "item_order":
+		default:
+			return fmt.Errorf("Unknown argument: %s", k)
+		}
+	}
+	return nil
+}
+
+func (s *Server) applyFilterArgs(f *Filter, args map[string]interface{}) error {
+	for k, v := range args {
+		switch k {
+		case "name":
+			f.Name = fmt.Sprint(v)
+		case "query":
+			f.Query = fmt.Sprint(v)
+		case "color":
+			c, ok := v.(float64)
+			if !ok || c < 30 || 49 < c {
+				return errInvalidArgument
+			}
+			f.Color = uint(c)
+		case "is_favorite":
+			b, ok := v.(bool)
+			if !ok {
+				return errInvalidArgument
+			}
+			f.Favorite = b
+		case "item_order":
+		default:
+			return fmt.Errorf("Unknown argument: %s", k)
+		}
+	}
+	return nil
+}
+
+func (s *Server) listComments(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	taskID, hasTask := parseID(q.Get("task_id"))
+	projectID, hasProject := parseID(q.Get("project_id"))
+	if !hasTask && !hasProject {
+		http.Error(w, "Required argument is missing", http.StatusBadRequest)
+		return
+	}
+
+	list := []*Comment{}
+	for _, id := range sortedIDs(s.comments) {
+		c := s.comments[id]
+		if c.deleted {
+			continue
+		}
+		if (hasTask && c.TaskID == taskID) || (hasProject && c.ProjectID == projectID) {
+			list = append(list, c)
+		}
+	}
+	writeJSON(w, list)
+}
+
+func (s *Server) createComment(w http.ResponseWriter, r *http.Request) {
+	var args struct {
+		TaskID     interface{} `json:"task_id"`
+		ProjectID  interface{} `json:"project_id"`
+		Content    string      `json:"content"`
+		Attachment *Attachment `json:"attachment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c := &Comment{Content: args.Content, Attachment: args.Attachment}
+	c.TaskID, _ = toID(args.TaskID)
+	c.ProjectID, _ = toID(args.ProjectID)
+	if c.Content == "" || (c.TaskID == "") == (c.ProjectID == "") {
+		http.Error(w, "Required argument is missing", http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.task(c.TaskID); c.TaskID != "" && !ok {
+		http.Error(w, "Task not found", http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.project(c.ProjectID); c.ProjectID != "" && !ok {
+		http.Error(w, "Project not found", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, s.insertComment(c))
+}
+
+func (s *Server) serveComment(w http.ResponseWriter, r *http.Request, idString string) {
+	id, _ := parseID(idString)
+	c, ok := s.comments[id]
+	if !ok || c.deleted {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, c)
+	case "POST":
+		var args struct {
+			Content string `json:"content"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if args.Content == "" {
+			http.Error(w, "Required argument is missing", http.StatusBadRequest)
+			return
+		}
+		c.Content = args.Content
+		s.touch(&c.record)
+		s.logComment(c, "updated")
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE":
+		s.deleteComment(c)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) serveUpload(w http.ResponseWriter, r *http.Request) {
+	f, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Required argument is missing", http.StatusBadRequest)
+		return
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := header.Filename
+	if v := r.FormValue("file_name"); v != "" {
+		name = v
+	}
+	fileType := header.Header.Get("Content-Type")
+	resourceType := "file"
+	if strings.HasPrefix(fileType, "image/") {
+		resourceType = "image"
+	}
+
+	p := fmt.Sprintf("%s/%s/%s", filesPrefix, s.newID(), url.PathEscape(name))
+	s.files[p] = data
+	writeJSON(w, &Attachment{
+		FileName:     name,
+		FileType:     fileType,
+		FileSize:     len(data),
+		FileURL:      s.URL + p,
+		ResourceType: resourceType,
+		UploadState:  "completed",
+	})
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	data, ok := s.files[r.URL.EscapedPath()]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if isBackup(r.URL.Path) && r.URL.Query().Get("token") != backupToken {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	w.Write(data)
+}
+
+type command struct {
+	Type   string                 `json:"type"`
+	Args   map[string]interface{} `json:"args"`
+	UUID   string                 `json:"uuid"`
+	TempID string                 `json:"temp_id"`
+}
+
+func (s *Server) serveSync(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out := map[string]interface{}{}
+	if v := r.PostForm.Get("commands"); v != "" {
+		commands := []command{}
+		if err := json.Unmarshal([]byte(v), &commands); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		status := map[string]interface{}{}
+		tempIDs := map[string]ID{}
+		for _, c := range commands {
+			if err := s.runCommand(c, tempIDs); err != nil {
+				status[c.UUID] = err
+			} else {
+				status[c.UUID] = "ok"
+			}
+		}
+		out["sync_status"] = status
+		out["temp_id_mapping"] = tempIDs
+	}
+
+	if token := r.PostForm.Get("sync_token"); token != "" {
+		resourceTypes := []string{}
+		if v := r.PostForm.Get("resource_types"); v != "" {
+			if err := json.Unmarshal([]byte(v), &resourceTypes); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		s.readResources(out, token, resourceTypes)
+	}
+	writeJSON(w, out)
+}
+
+// readResources fills out with the requested resources changed since the
+// version encoded in token. An unknown token or "*" yields a full sync.
+func (s *Server) readResources(out map[string]interface{}, token string, resourceTypes []string) {
+	since, err := strconv.Atoi(token)
+	if err != nil || since > s.version {
+		since = 0
+	}
+	full := since == 0
+
+	wants := func(name string) bool {
+		for _, v := range resourceTypes {
+			if v == name || v == "all" {
+				return true
+			}
+		}
+		return false
+	}
+	changed := func(r record) bool {
+		return r.version > since && !(full && r.deleted)
+	}
+
+	if wants("user") {
+		timezone := s.Timezone
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		out["user"] = map[string]interface{}{
+			"id": UserID, "full_name": "Test User", "is_premium": s.Premium, "token": s.Token,
+			"tz_info": map[string]interface{}{"timezone": timezone},
+		}
+	}
+	if wants("items") {
+		items := []interface{}{}
+		for _, id := range sortedIDs(s.tasks) {
+			if t := s.tasks[id]; changed(t.record) && !(full && t.Completed) {
+				items = append(items, syncItem(t))
+			}
+		}
+		out["items"] = items
+	}
+	if wants("projects") {
+		projects := []interface{}{}
+		for _, id := range sortedIDs(s.projects) {
+			if p := s.projects[id]; changed(p.record) {
+				projects = append(projects, map[string]interface{}{
+					"id": p.ID, "parent_id": p.ParentID, "name": p.Name, "color": p.Color, "child_order": p.Order,
+					"is_favorite": boolInt(p.Favorite), "inbox_project": p.InboxProject,
+					"is_archived": boolInt(p.Archived), "is_deleted": boolInt(p.deleted),
+				})
+			}
+		}
+		out["projects"] = projects
+	}
+	if wants("labels") {
+		labels := []interface{}{}
+		for _, id := range sortedIDs(s.labels) {
+			if l := s.labels[id]; changed(l.record) {
+				labels = append(labels, map[string]interface{}{
+					"id": l.ID, "name": l.Name, "color": l.Color, "item_order": l.Order,
+					"is_favorite": boolInt(l.Favorite), "is_deleted": boolInt(l.deleted),
+				})
+			}
+		}
+		out["labels"] = labels
+	}
+	if wants("notes") {
+		notes := []interface{}{}
+		for _, id := range sortedIDs(s.comments) {
+			if c := s.comments[id]; changed(c.record) && c.TaskID != "" {
+				notes = append(notes, map[string]interface{}{
+					"id": c.ID, "item_id": c.TaskID, "content": c.Content, "posted": c.Posted, "posted_uid": c.PosterID,
+					"is_deleted": boolInt(c.deleted),
+				})
+			}
+		}
+		out["notes"] = notes
+	}
+	if wants("reminders") {
+		reminders := []interface{}{}
+		for _, id := range sortedIDs(s.reminders) {
+			if v := s.reminders[id]; changed(v.record) {
+				reminder := map[string]interface{}{
+					"id": v.ID, "item_id": v.TaskID, "type": v.Type, "is_deleted": boolInt(v.deleted),
+				}
+				if v.Type == "relative" {
+					reminder["minute_offset"] = v.MinuteOffset
+				} else {
+					reminder["due"] = v.Due
+				}
+				reminders = append(reminders, reminder)
+			}
+		}
+		out["reminders"] = reminders
+	}
+	if wants("filters") {
+		filters := []interface{}{}
+		for _, id := range sortedIDs(s.filters) {
+			if f := s.filters[id]; changed(f.record) {
+				filters = append(filters, map[string]interface{}{
+					"id": f.ID, "name": f.Name, "query": f.Query, "color": f.Color, "item_order": f.Order,
+					"is_favorite": boolInt(f.Favorite), "is_deleted": boolInt(f.deleted),
+				})
+			}
+		}
+		out["filters"] = filters
+	}
+	if wants("sections") {
+		sections := []interface{}{}
+		for _, id := range sortedIDs(s.sections) {
+			if v := s.sections[id]; changed(v.record) {
+				sections = append(sections, map[string]interface{}{
+					"id": v.ID, "project_id": v.ProjectID, "name": v.Name, "section_order": v.Order,
+					"is_deleted": boolInt(v.deleted),
+				})
+			}
+		}
+		out["sections"] = sections
+	}
+
+	out["sync_token"] = strconv.Itoa(s.version)
+	out["full_sync"] = full
+}
+
+func syncItem(t *Task) map[string]interface{} {
+	item := map[string]interface{}{
+		"id":              t.ID,
+		"content":         t.Content,
+		"project_id":      t.ProjectID,
+		"section_id":      t.SectionID,
+		"parent_id":       t.ParentID,
+		"responsible_uid": t.AssigneeID,
+		"assigned_by_uid": t.AssignerID,
+		"description":     t.Description,
+		"duration":        t.Duration,
+		"deadline":        t.Deadline,
+		"date_added":      t.Created,
+		"added_by_uid":    t.CreatorID,
+		"labels":          t.LabelIDs,
+		"priority":        t.Priority,
+		"child_order":     t.Order,
+		"checked":         boolInt(t.Completed),
+		"is_deleted":      boolInt(t.deleted),
+	}
+	if t.Due != nil {
+		item["due"] = t.Due
+	}
+	return item
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *Server) runCommand(c command, tempIDs map[string]ID) map[string]interface{} {
+	switch c.Type {
+	case "item_add":
+		t := &Task{}
+		if err := s.applyTaskArgs(t, c.Args, tempIDs); err != nil {
+			return commandError(20, err.Error())
+		}
+		if t.Content == "" {
+			return commandError(19, "Required argument is missing")
+		}
+		tempIDs[c.TempID] = s.insertTask(t).ID
+		return nil
+	case "item_update", "item_move", "item_close", "item_uncomplete", "item_delete":
+		id, _ := s.resolveID(c.Args["id"], tempIDs)
+		t, ok := s.task(id)
+		if !ok {
+			return commandError(22, "Item not found")
+		}
+
+		switch c.Type {
+		case "item_update", "item_move":
+			args := map[string]interface{}{}
+			for k, v := range c.Args {
+				if k != "id" {
+					args[k] = v
+				}
+			}
+			if err := s.applyTaskArgs(t, args, tempIDs); err != nil {
+				return commandError(20, err.Error())
+			}
+		case "item_close":
+			s.completeTask(t, true)
+		case "item_uncomplete":
+			s.completeTask(t, false)
+		case "item_delete":
+			s.deleteTask(t)
+		}
+		s.touch(&t.record)
+		return nil
+	case "project_add":
+		p := &Project{}
+		if err := s.applyProjectArgs(p, c.Args, tempIDs); err != nil {
+			return commandError(20, err.Error())
+		}
+		if p.Name == "" {
+			return commandError(19, "Required argument is missing")
+		}
+		tempIDs[c.TempID] = s.insertProject(p).ID
+		return nil
+	case "project_update", "project_delete", "project_archive", "project_unarchive":
+		id, _ := s.resolveID(c.Args["id"], tempIDs)
+		p, ok := s.project(id)
+		if !ok {
+			return commandError(21, "Project not found")
+		}
+
+		switch c.Type {
+		case "project_update":
+			args := map[string]interface{}{}
+			for k, v := range c.Args {
+				if k != "id" {
+					args[k] = v
+				}
+			}
+			if err := s.applyProjectArgs(p, args, tempIDs); err != nil {
+				return commandError(20, err.Error())
+			}
+			s.touch(&p.record)
+		case "project_delete":
+			if p.InboxProject {
+				return commandError(20, "Inbox project cannot be deleted")
+			}
+			s.deleteProject(p)
+		case "project_archive", "project_unarchive":
+			if p.InboxProject {
+				return commandError(20, "Inbox project cannot be archived")
+			}
+			s.archiveProject(p, c.Type == "project_archive")
+		}
+		return nil
+	case "label_add":
+		l := &Label{}
+		if err := s.applyLabelArgs(l, c.Args); err != nil {
+			return commandError(20, err.Error())
+		}
+		if l.Name == "" {
+			return commandError(19, "Required argument is missing")
+		}
+		tempIDs[c.TempID] = s.insertLabel(l).ID
+		return nil
+	case "label_update", "label_delete":
+		id, _ := s.resolveID(c.Args["id"], tempIDs)
+		l, ok := s.label(id)
+		if !ok {
+			return commandError(24, "Label not found")
+		}
+
+		if c.Type == "label_delete" {
+			s.deleteLabel(l)
+			return nil
+		}
+
+		args := map[string]interface{}{}
+		for k, v := range c.Args {
+			if k != "id" {
+				args[k] = v
+			}
+		}
+		if err := s.applyLabelArgs(l, args); err != nil {
+			return commandError(20, err.Error())
+		}
+		s.touch(&l.record)
+		return nil
+	case "filter_add":
+		f := &Filter{}
+		if err := s.applyFilterArgs(f, c.Args); err != nil {
+			return commandError(20, err.Error())
+		}
+		if f.Name == "" || f.Query == "" {
+			return commandError(19, "Required argument is missing")
+		}
+		tempIDs[c.TempID] = s.insertFilter(f).ID
+		return nil
+	case "filter_update", "filter_delete":
+		id, _ := s.resolveID(c.Args["id"], tempIDs)
+		f, ok := s.filters[id]
+		if !ok || f.deleted {
+			return commandError(26, "Filter not found")
+		}
+
+		if c.Type == "filter_delete" {
+			f.deleted = true
+			s.touch(&f.record)
+			return nil
+		}
+
+		args := map[string]interface{}{}
+		for k, v := range c.Args {
+			if k != "id" {
+				args[k] = v
+			}
+		}
+		if err := s.applyFilterArgs(f, args); err != nil {
+			return commandError(20, err.Error())
+		}
+		s.touch(&f.record)
+		return nil
+	case "reminder_add":
+		r := &Reminder{}
+		if err := s.applyReminderArgs(r, c.Args, tempIDs); err != nil {
+			return commandError(20, err.Error())
+		}
+		if r.TaskID == "" || r.Type == "" {
+			return commandError(19, "Required argument is missing")
+		}
+		if t, _ := s.task(r.TaskID); r.Type == "relative" && t.Due == nil {
+			return commandError(20, "Relative reminders require a due date")
+		}
+		if r.Type == "absolute" && r.Due == nil {
+			return commandError(19, "Required argument is missing")
+		}
+		r.ID = s.newID()
+		s.touch(&r.record)
+		s.reminders[r.ID] = r
+		tempIDs[c.TempID] = r.ID
+		return nil
+	case "reminder_update", "reminder_delete":
+		id, _ := s.resolveID(c.Args["id"], tempIDs)
+		r, ok := s.reminders[id]
+		if !ok || r.deleted {
+			return commandError(25, "Reminder not found")
+		}
+
+		if c.Type == "reminder_delete" {
+			r.deleted = true
+			s.touch(&r.record)
+			return nil
+		}
+
+		args := map[string]interface{}{}
+		for k, v := range c.Args {
+			if k != "id" {
+				args[k] = v
+			}
+		}
+		if err := s.applyReminderArgs(r, args, tempIDs); err != nil {
+			return commandError(20, err.Error())
+		}
+		s.touch(&r.record)
+		return nil
+	default:
+		return commandError(34, fmt.Sprintf("Unsupported command: %s", c.Type))
+	}
+}
+
+func (s *Server) applyReminderArgs(r *Reminder, args map[string]interface{}, tempIDs map[string]ID) error {
+	for k, v := range args {
+		switch k {
+		case "item_id":
+			id, _ := s.resolveID(v, tempIDs)
+			if _, ok := s.task(id); !ok {
+				return errInvalidArgument
+			}
+			r.TaskID = id
+		case "type":
+			if v != "relative" && v != "absolute" {
+				return errInvalidArgument
+			}
+			r.Type = v.(string)
+		case "minute_offset":
+			n, ok := v.(float64)
+			if !ok || n < 0 {
+				return errInvalidArgument
+			}
+			r.MinuteOffset = int(n)
+		case "due":
+			due, _ := v.(map[string]interface{})
+			if due == nil {
+				return errInvalidArgument
+			}
+			r.Due = &Due{}
+			r.Due.String, _ = due["string"].(string)
+			r.Due.Date, _ = due["date"].(string)
+			if r.Due.String == "" {
+				r.Due.String = r.Due.Date
+			}
+		default:
+			return fmt.Errorf("Unknown argument: %s", k)
+		}
+	}
+
+	if r.Type == "relative" {
+		r.Due = nil
+	} else {
+		r.MinuteOffset = 0
+	}
+	return nil
+}
+
+// resolveID accepts numeric IDs as well as temp IDs created earlier in the
+// same batch of commands.
+func (s *Server) resolveID(v interface{}, tempIDs map[string]ID) (ID, bool) {
+	if tempID, ok := v.(string); ok {
+		if id, ok := tempIDs[tempID]; ok {
+			return id, true
+		}
+	}
+	return toID(v)
+}
+
+// completedTasks returns the completed tasks matching the project_id, since
+// and until parameters, most recently completed first.
+func (s *Server) completedTasks(q url.Values, format string) ([]*Task, error) {
+	var since, until time.Time
+	var err error
+	if v := q.Get("since"); v != "" {
+		if since, err = time.Parse(format, v); err != nil {
+			return nil, errInvalidArgument
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if until, err = time.Parse(format, v); err != nil {
+			return nil, errInvalidArgument
+		}
+	}
+	projectID := ID(q.Get("project_id"))
+
+	list := []*Task{}
+	for _, id := range sortedIDs(s.tasks) {
+		t := s.tasks[id]
+		switch {
+		case t.deleted || !t.Completed:
+		case projectID != "" && t.ProjectID != projectID:
+		case !since.IsZero() && t.completedAt.Before(since):
+		case !until.IsZero() && t.completedAt.After(until):
+		default:
+			list = append(list, t)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].completedAt.After(list[j].completedAt)
+	})
+	return list, nil
+}
+
+// serveCompleted answers /completed/get_all, which pages by offset and
+// returns at most 200 tasks at a time.
+func (s *Server) serveCompleted(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	list, err := s.completedTasks(q, "2006-01-02T15:04")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 30
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset > len(list) {
+		offset = len(list)
+	}
+	if offset+limit < len(list) {
+		list = list[:offset+limit]
+	}
+
+	items := []interface{}{}
+	for _, t := range list[offset:] {
+		items = append(items, map[string]interface{}{
+			"id": t.ID, "task_id": t.ID, "content": t.Content,
+			"project_id": t.ProjectID, "section_id": t.SectionID,
+			"completed_date": t.completedAt.Format(time.RFC3339),
+		})
+	}
+	writeJSON(w, map[string]interface{}{"items": items, "projects": map[string]interface{}{}})
+}
+
+func (s *Server) serveQuickAdd(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	text := r.PostForm.Get("text")
+	if text == "" {
+		http.Error(w, "Required argument is missing", http.StatusBadRequest)
+		return
+	}
+
+	t := &Task{}
+	words := []string{}
+	for _, word := range strings.Fields(text) {
+		switch {
+		case strings.HasPrefix(word, "#") && s.projectByName(word[1:]) != "":
+			t.ProjectID = s.projectByName(word[1:])
+		case strings.HasPrefix(word, "@") && s.labelByName(word[1:]) != "":
+			t.LabelIDs = append(t.LabelIDs, s.labelByName(word[1:]))
+		default:
+			words = append(words, word)
+		}
+	}
+	t.Content = strings.Join(words, " ")
+
+	writeJSON(w, s.insertTask(t))
+}
+
+func (s *Server) projectByName(name string) ID {
+	for id, p := range s.projects {
+		if !p.deleted && strings.EqualFold(p.Name, name) {
+			return id
+		}
+	}
+	return ""
+}
+
+func (s *Server) labelByName(name string) ID {
+	for id, l := range s.labels {
+		if !l.deleted && strings.EqualFold(l.Name, name) {
+			return id
+		}
+	}
+	return ""
+}
+
+var errInvalidArgument = fmt.Errorf("Invalid argument value")
+
+func commandError(code int, message string) map[string]interface{} {
+	return map[string]interface{}{"error_code": code, "error": message}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func splitPath(p string) []string {
+	return strings.Split(strings.Trim(p, "/"), "/")
+}
+
+func parseID(s string) (ID, bool) {
+	if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+		return "", false
+	}
+	return ID(s), true
+}
+
+func toID(v interface{}) (ID, bool) {
+	switch v := v.(type) {
+	case float64:
+		return parseID(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		return parseID(v)
+	default:
+		return "", false
+	}
+}
+
+func containsID(list []ID, id ID) bool {
+	for _, v := range list {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedIDs(m interface{}) []ID {
+	ids := []ID{}
+	switch m := m.(type) {
+	case map[ID]*Task:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[ID]*Project:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[ID]*Section:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[ID]*Label:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[ID]*Comment:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[ID]*Reminder:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[ID]*Filter:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) < len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
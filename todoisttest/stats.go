@@ -0,0 +1,122 @@
+package todoisttest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Goals used when the Server leaves them zero.
+const (
+	defaultDailyGoal  = 5
+	defaultWeeklyGoal = 25
+)
+
+// stats computes the productivity stats from the completion times of the
+// tasks. Days and weeks are in UTC, and weeks start on Monday.
+func (s *Server) stats(now time.Time) map[string]interface{} {
+	daily, weekly := s.DailyGoal, s.WeeklyGoal
+	if daily == 0 {
+		daily = defaultDailyGoal
+	}
+	if weekly == 0 {
+		weekly = defaultWeeklyGoal
+	}
+
+	days := map[string]int{}
+	weeks := map[string]int{}
+	total := 0
+	first := now
+	for _, t := range s.tasks {
+		if t.deleted || !t.Completed {
+			continue
+		}
+		total++
+		days[t.completedAt.Format(dateFormat)]++
+		weeks[weekStart(t.completedAt).Format(dateFormat)]++
+		if t.completedAt.Before(first) {
+			first = t.completedAt
+		}
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	dayItems := []interface{}{}
+	for i := 0; i < 7; i++ {
+		d := today.AddDate(0, 0, -i).Format(dateFormat)
+		dayItems = append(dayItems, map[string]interface{}{"date": d, "total_completed": days[d], "items": []interface{}{}})
+	}
+
+	thisWeek := weekStart(now)
+	weekItems := []interface{}{}
+	for i := 0; i < 4; i++ {
+		from := thisWeek.AddDate(0, 0, -7*i)
+		weekItems = append(weekItems, map[string]interface{}{
+			"from": from.Format(dateFormat), "to": from.AddDate(0, 0, 6).Format(dateFormat),
+			"total_completed": weeks[from.Format(dateFormat)], "items": []interface{}{},
+		})
+	}
+
+	currentDaily, maxDaily := streaks(days, daily, first.UTC().Truncate(24*time.Hour), today, 1)
+	currentWeekly, maxWeekly := streaks(weeks, weekly, weekStart(first), thisWeek, 7)
+
+	return map[string]interface{}{
+		"karma":           float64(total * 5),
+		"karma_trend":     "up",
+		"completed_count": total,
+		"days_items":      dayItems,
+		"week_items":      weekItems,
+		"goals": map[string]interface{}{
+			"daily_goal": daily, "weekly_goal": weekly,
+			"current_daily_streak": currentDaily, "max_daily_streak": maxDaily,
+			"current_weekly_streak": currentWeekly, "max_weekly_streak": maxWeekly,
+			"ignore_days": []int{6, 7}, "vacation_mode": 0,
+		},
+	}
+}
+
+const dateFormat = "2006-01-02"
+
+func weekStart(t time.Time) time.Time {
+	t = t.UTC().Truncate(24 * time.Hour)
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+// streaks returns the current and the longest run of periods from first to
+// last reaching the goal. The current period only breaks the current run
+// once it is over.
+func streaks(counts map[string]int, goal int, first, last time.Time, days int) (current, max map[string]interface{}) {
+	streak := func(count int, start, end time.Time) map[string]interface{} {
+		if count == 0 {
+			return map[string]interface{}{"count": 0, "start": "", "end": ""}
+		}
+		return map[string]interface{}{"count": count, "start": start.Format(dateFormat), "end": end.Format(dateFormat)}
+	}
+
+	var run, best int
+	var start, bestStart, bestEnd time.Time
+	for t := first; !t.After(last); t = t.AddDate(0, 0, days) {
+		if counts[t.Format(dateFormat)] < goal {
+			if !t.Equal(last) {
+				run = 0
+			}
+			continue
+		}
+		if run == 0 {
+			start = t
+		}
+		run++
+		if run > best {
+			best, bestStart, bestEnd = run, start, t
+		}
+	}
+
+	end := last
+	if counts[last.Format(dateFormat)] < goal {
+		end = last.AddDate(0, 0, -days)
+	}
+	return streak(run, start, end), streak(best, bestStart, bestEnd)
+}
+
+// serveStats answers the productivity stats of both API generations.
+func (s *Server) serveStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.stats(time.Now()))
+}
@@ -0,0 +1,278 @@
+package todoisttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	unifiedPrefix   = "/api/v1"
+	defaultPageSize = 50
+)
+
+// UnifiedURL returns the base URL of the unified API, to pass to both
+// todoist.WithRESTBaseURL and todoist.WithSyncBaseURL together with
+// todoist.WithAPIVersion(todoist.UnifiedAPI).
+func (s *Server) UnifiedURL() string {
+	return s.URL + unifiedPrefix
+}
+
+// serveUnified answers unified API requests by running the matching v1
+// handler and translating its response: IDs become strings, some fields are
+// renamed and lists are split into cursor-paginated pages.
+func (s *Server) serveUnified(w http.ResponseWriter, r *http.Request) {
+	elm := splitPath(strings.TrimPrefix(r.URL.Path, unifiedPrefix))
+	legacy := r.Clone(r.Context())
+	rec := httptest.NewRecorder()
+
+	paginate := r.Method == "GET" && (len(elm) == 1 || (len(elm) == 3 && elm[2] == "collaborators"))
+	switch {
+	case r.Method == "GET" && len(elm) == 2 && elm[0] == "tasks" && elm[1] == "filter":
+		q := legacy.URL.Query()
+		q.Set("filter", q.Get("query"))
+		q.Del("query")
+		legacy.URL.RawQuery = q.Encode()
+
+		paginate = true
+		s.serveREST(rec, legacy, []string{"tasks"})
+	case r.Method == "POST" && len(elm) == 2 && elm[0] == "tasks" && elm[1] == "quick":
+		args := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		form := url.Values{}
+		for k, v := range args {
+			form.Set(k, toString(v))
+		}
+		legacy.Body = ioutil.NopCloser(strings.NewReader(form.Encode()))
+		legacy.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		s.serveQuickAdd(rec, legacy)
+	case r.Method == "GET" && len(elm) == 3 && elm[0] == "tasks" && elm[1] == "completed" && elm[2] == "by_completion_date":
+		s.serveUnifiedCompleted(w, r)
+		return
+	case r.Method == "GET" && len(elm) == 3 && elm[0] == "tasks" && elm[1] == "completed" && elm[2] == "stats":
+		s.serveStats(w, r)
+		return
+	case r.Method == "GET" && len(elm) == 1 && elm[0] == "backups":
+		s.serveBackups(w, r)
+		return
+	case r.Method == "GET" && len(elm) == 1 && elm[0] == "activities":
+		s.serveUnifiedActivity(w, r)
+		return
+	case len(elm) == 1 && elm[0] == "sync":
+		s.serveSync(rec, legacy)
+	case r.Method == "POST" && len(elm) == 1 && elm[0] == "uploads":
+		s.serveUpload(rec, legacy)
+	default:
+		s.serveREST(rec, legacy, elm)
+	}
+
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+
+	body := rec.Body.Bytes()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		w.WriteHeader(rec.Code)
+		w.Write(body)
+		return
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	v = s.unify(v)
+	if list, ok := v.([]interface{}); ok && paginate {
+		v = s.page(list, r.URL.Query())
+	}
+
+	w.WriteHeader(rec.Code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// serveUnifiedCompleted lists completed tasks, which the unified API returns
+// as tasks with a completion time in pages of items.
+func (s *Server) serveUnifiedCompleted(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("since") == "" || q.Get("until") == "" {
+		http.Error(w, "since and until are required", http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := s.completedTasks(q, "2006-01-02T15:04:05Z")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list := []interface{}{}
+	for _, t := range tasks {
+		v := s.unifyObject(t).(map[string]interface{})
+		v["completed_at"] = t.completedAt.Format("2006-01-02T15:04:05.000000Z")
+		list = append(list, v)
+	}
+
+	page := s.page(list, q)
+	writeJSON(w, map[string]interface{}{"items": page["results"], "next_cursor": page["next_cursor"]})
+}
+
+// serveUnifiedActivity lists the events of the activity log in pages.
+func (s *Server) serveUnifiedActivity(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	list := s.activity(q)
+	for i := range list {
+		list[i] = s.unifyObject(list[i])
+	}
+	writeJSON(w, s.page(list, q))
+}
+
+// unifyObject converts an object of the fake into its unified API form.
+func (s *Server) unifyObject(obj interface{}) interface{} {
+	data, _ := json.Marshal(obj)
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.Decode(&v)
+
+	return s.unify(v)
+}
+
+// page returns the part of list selected by the cursor and limit
+// parameters. Cursors are plain offsets.
+func (s *Server) page(list []interface{}, q url.Values) map[string]interface{} {
+	limit := s.PageSize
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && 0 < n && n < limit {
+		limit = n
+	}
+
+	offset, _ := strconv.Atoi(q.Get("cursor"))
+	if offset > len(list) {
+		offset = len(list)
+	}
+
+	end := offset + limit
+	var next interface{}
+	if end < len(list) {
+		next = strconv.Itoa(end)
+	} else {
+		end = len(list)
+	}
+
+	return map[string]interface{}{"results": list[offset:end], "next_cursor": next}
+}
+
+// unify converts a decoded v1 response into its unified API form.
+func (s *Server) unify(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = s.unify(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		out := map[string]interface{}{}
+		_, isTask := v["content"]
+		_, isProject := v["inbox_project"]
+		_, hasFavorite := v["favorite"]
+		isProject = isProject && hasFavorite
+		_, hasProjectID := v["project_id"]
+		_, hasName := v["name"]
+		isSection := hasProjectID && hasName
+		_, isComment := v["posted"]
+
+		for k, elm := range v {
+			switch {
+			case k == "temp_id_mapping":
+				mapping := map[string]interface{}{}
+				for tempID, id := range elm.(map[string]interface{}) {
+					mapping[tempID] = toString(id)
+				}
+				out[k] = mapping
+			case k == "label_ids" || (k == "labels" && isTask):
+				names := []interface{}{}
+				list, _ := elm.([]interface{})
+				for _, id := range list {
+					if l, ok := s.labels[ID(toString(id))]; ok {
+						names = append(names, l.Name)
+					}
+				}
+				out["labels"] = names
+			case k == "assignee" && isTask:
+				out["responsible_uid"] = stringID(elm)
+			case k == "assigner" && isTask:
+				out["assigned_by_uid"] = stringID(elm)
+			case k == "creator" && isTask:
+				out["added_by_uid"] = stringID(elm)
+			case (k == "created" || k == "date_added") && isTask:
+				out["added_at"] = elm
+			case k == "id" || strings.HasSuffix(k, "_id") || strings.HasSuffix(k, "_uid"):
+				out[k] = stringID(elm)
+			case k == "posted":
+				out["posted_at"] = elm
+			case k == "attachment":
+				out["file_attachment"] = elm
+			case k == "completed":
+				out["checked"] = elm
+			case k == "checked" || k == "is_deleted":
+				out[k] = toString(elm) == "1" || elm == true
+			case k == "favorite" || (k == "inbox_project" && isProject):
+				out["is_"+k] = elm
+			case k == "comment_count" && isTask:
+				out["note_count"] = elm
+			case k == "order" && (isTask || isProject):
+				out["child_order"] = elm
+			case k == "order" && isSection:
+				out["section_order"] = elm
+			default:
+				out[k] = s.unify(elm)
+			}
+		}
+		if c, ok := s.comments[ID(toString(v["id"]))]; ok && isComment {
+			out["posted_uid"] = toString(c.PosterID)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// stringID turns numeric IDs into strings and leaves other values as is.
+func stringID(v interface{}) interface{} {
+	if n, ok := v.(json.Number); ok {
+		return n.String()
+	}
+	return v
+}
@@ -16,11 +16,14 @@ type UI struct {
 	table  *tview.Table
 	status *tview.TextView
 	footer *tview.Flex
+
+	headers []string
 }
 
 func NewUI() *UI {
 	var u UI
 	u.Application = tview.NewApplication()
+	u.headers = []string{"ID", "DueDate", "Pri", "Project", "Content"}
 
 	u.table = tview.NewTable()
 	u.table.SetFixed(1, 5).
@@ -64,7 +67,7 @@ func modal(primitive tview.Primitive, width, height int) tview.Primitive {
 func (u *UI) Init() {
 	u.table.Clear()
 	u.table.ScrollToBeginning().Select(1, 0)
-	headers := []string{"ID", "DueDate", "Pri", "Project", "Content"}
+	headers := u.headers
 	for i, header := range headers {
 		c := tview.NewTableCell(header).SetSelectable(false)
 		c.SetAttributes(tcell.AttrBold).
@@ -79,6 +82,11 @@ func (u *UI) Init() {
 	}
 }
 
+// SetHeaders sets the column headers shown by Init.
+func (u *UI) SetHeaders(headers []string) {
+	u.headers = headers
+}
+
 func (u *UI) SetInputCapture(f func(*tcell.EventKey) *tcell.EventKey) {
 	u.table.SetInputCapture(f)
 }
@@ -88,12 +96,30 @@ func (u *UI) GetSelection() int {
 	return r - 1
 }
 
+// Select selects row r and scrolls it into view.
+func (u *UI) Select(r int) {
+	u.table.Select(r+1, 0)
+}
+
 func (u *UI) RenderRow(r int, cells ...*tview.TableCell) {
 	for i, c := range cells {
 		u.table.SetCell(r+1, i, c)
 	}
 }
 
+// RenderHeader renders a row that groups the rows below it and cannot be
+// selected.
+func (u *UI) RenderHeader(r int, text string) {
+	columns := u.table.GetColumnCount()
+	for i := 0; i < columns; i++ {
+		c := tview.NewTableCell("").SetSelectable(false)
+		if i == columns-1 {
+			c.SetText(text).SetAttributes(tcell.AttrBold | tcell.AttrUnderline)
+		}
+		u.table.SetCell(r+1, i, c)
+	}
+}
+
 func (u *UI) RemoveRow(r int) {
 	u.table.RemoveRow(r + 1)
 }
@@ -103,10 +129,10 @@ func (u *UI) PopupConfirm(message string, buttonLabels []string, callbackFunc fu
 		SetText(message).SetTextColor(tcell.ColorRed).
 		AddButtons(buttonLabels).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			callbackFunc(buttonLabel)
-
 			u.SetFocus(u.table)
 			u.pages.HidePage("modal").RemovePage("modal")
+
+			callbackFunc(buttonLabel)
 		})
 
 	u.pages.AddPage("modal", confirm, true, true)
@@ -136,20 +162,107 @@ func (u *UI) PopupInput(title, text string, callbackFunc func(string)) {
 	})
 
 	input.SetDoneFunc(func(key tcell.Key) {
+		u.SetFocus(u.table)
+		u.pages.HidePage("modal").RemovePage("modal")
+
 		switch key {
 		case tcell.KeyEnter:
 			callbackFunc(strings.TrimSpace(input.GetText()))
 		}
+	})
+
+	u.pages.AddPage("modal", modal(input, innterWidth+2, 3), true, true)
+	u.SetFocus(input)
+}
+
+// PopupChecklist shows a checkbox for each item followed by an input field.
+// On save, callbackFunc receives the checked state of every item and the
+// text of the input field.
+func (u *UI) PopupChecklist(title string, items []string, checked []bool, inputLabel string, callbackFunc func([]bool, string)) {
+	_, _, width, height := u.pages.GetRect()
+	innerWidth := int(float32(width) * 0.8)
+	// The form scrolls to the focused item when the checkboxes, the input
+	// field and the buttons do not fit.
+	innerHeight := len(items) + 2
+	if innerHeight > height-4 {
+		innerHeight = height - 4
+	}
+
+	state := make([]bool, len(items))
+	copy(state, checked)
+
+	form := tview.NewForm()
+	form.SetItemPadding(0).
+		SetFieldBackgroundColor(tcell.ColorBlack).
+		SetTitle(fmt.Sprintf(" %s ", title)).SetTitleAlign(tview.AlignLeft).
+		SetBorder(true).SetBorderPadding(0, 0, 1, 1)
 
+	for i, item := range items {
+		i := i
+		form.AddCheckbox(tview.Escape(item), state[i], func(checked bool) {
+			state[i] = checked
+		})
+	}
+
+	input := tview.NewInputField().SetLabel(inputLabel)
+	form.AddFormItem(input)
+
+	closeFunc := func() {
 		u.SetFocus(u.table)
 		u.pages.HidePage("modal").RemovePage("modal")
+	}
+	form.AddButton("Save", func() {
+		closeFunc()
+		callbackFunc(state, strings.TrimSpace(input.GetText()))
 	})
+	form.AddButton("Cancel", closeFunc)
+	form.SetCancelFunc(closeFunc)
 
-	u.pages.AddPage("modal", modal(input, innterWidth+2, 3), true, true)
-	u.SetFocus(input)
+	u.pages.AddPage("modal", modal(form, innerWidth+2, innerHeight+2), true, true)
+	u.SetFocus(form)
+}
+
+// PopupList shows the items as a list and calls callbackFunc with the index
+// of the chosen one.
+func (u *UI) PopupList(title string, items []string, callbackFunc func(int)) {
+	_, _, width, height := u.pages.GetRect()
+	innerWidth := int(float32(width) * 0.8)
+	innerHeight := len(items)
+	if innerHeight > height-4 {
+		innerHeight = height - 4
+	}
+
+	list := tview.NewList()
+	list.ShowSecondaryText(false).
+		SetTitle(fmt.Sprintf(" %s ", title)).SetTitleAlign(tview.AlignLeft).
+		SetBorder(true).SetBorderPadding(0, 0, 1, 1)
+
+	for _, item := range items {
+		list.AddItem(item, "", 0, nil)
+	}
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		u.SetFocus(u.table)
+		u.pages.HidePage("modal").RemovePage("modal")
+
+		callbackFunc(index)
+	})
+	list.SetDoneFunc(func() {
+		u.SetFocus(u.table)
+		u.pages.HidePage("modal").RemovePage("modal")
+	})
+
+	u.pages.AddPage("modal", modal(list, innerWidth+2, innerHeight+2), true, true)
+	u.SetFocus(list)
 }
 
 func (u *UI) Popup(title, content string) {
+	u.PopupWithActions(title, content, nil)
+}
+
+// PopupWithActions is a Popup that also reacts to the keys of actions by
+// closing itself and running the matching action.
+func (u *UI) PopupWithActions(title, content string, actions map[rune]func()) {
 	_, _, width, _ := u.pages.GetRect()
 	innerWidth := int(float32(width) * 0.8)
 	innerHeight := len(tview.WordWrap(content, innerWidth))
@@ -166,6 +279,18 @@ func (u *UI) Popup(title, content string) {
 		u.SetFocus(u.table)
 		u.pages.HidePage("modal").RemovePage("modal")
 	})
+	text.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		action, ok := actions[event.Rune()]
+		if !ok || event.Key() != tcell.KeyRune {
+			return event
+		}
+
+		u.SetFocus(u.table)
+		u.pages.HidePage("modal").RemovePage("modal")
+
+		action()
+		return nil
+	})
 
 	u.pages.AddPage("modal", modal(text, innerWidth+2, innerHeight+2), true, true)
 	u.SetFocus(text)
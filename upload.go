@@ -0,0 +1,143 @@
+package todoist
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+)
+
+// Attachment is a file attached to a comment.
+type Attachment struct {
+	FileName     string `json:"file_name"`
+	FileType     string `json:"file_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+	FileURL      string `json:"file_url"`
+	ResourceType string `json:"resource_type,omitempty"`
+	UploadState  string `json:"upload_state,omitempty"`
+}
+
+// UploadFile uploads the content of r as a file named name. The returned
+// attachment is meant to be passed to AddComment.
+func (c *Client) UploadFile(name string, r io.Reader) (*Attachment, error) {
+	return c.UploadFileContext(context.Background(), name, r)
+}
+
+func (c *Client) UploadFileContext(ctx context.Context, name string, r io.Reader) (*Attachment, error) {
+	if name == "" {
+		return nil, invalid("file name", "is required")
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, escapeQuotes(name)))
+	header.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, err
+	}
+	if err := mw.WriteField("file_name", name); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	ro := NewRequestOption()
+	ro.Body = &body
+	ro.Headers["Content-Type"] = mw.FormDataContentType()
+
+	u := c.syncEndpoint("/uploads/add")
+	if c.apiVersion == UnifiedAPI {
+		u = c.syncEndpoint("/uploads")
+	}
+
+	resp, err := c.httpRequest(ctx, "POST", u, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Attachment)
+	return out, decodeJSON(resp, out)
+}
+
+// AttachFile uploads a local file and attaches it to the task in a new
+// comment. An empty content defaults to the file name.
+func (c *Client) AttachFile(taskID ID, path, content string) (*Comment, error) {
+	return c.AttachFileContext(context.Background(), taskID, path, content)
+}
+
+func (c *Client) AttachFileContext(ctx context.Context, taskID ID, path, content string) (*Comment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	name := filepath.Base(path)
+	attachment, err := c.UploadFileContext(ctx, name, f)
+	if err != nil {
+		return nil, err
+	}
+
+	if content == "" {
+		content = name
+	}
+	return c.AddCommentContext(ctx, &AddCommentParams{TaskID: taskID, Content: content, Attachment: attachment})
+}
+
+func escapeQuotes(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// String describes the attachment as its name, type and size.
+func (a *Attachment) String() string {
+	details := a.FileType
+	if a.FileSize > 0 {
+		if details != "" {
+			details += ", "
+		}
+		details += formatSize(a.FileSize)
+	}
+
+	if details == "" {
+		return a.FileName
+	}
+	return fmt.Sprintf("%s (%s)", a.FileName, details)
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,96 @@
+package todoist
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haccht/todoist/todoisttest"
+)
+
+func TestAttachFile(t *testing.T) {
+	c, s := newTestClient(t)
+	task := s.AddTask(todoisttest.Task{Content: "with a log"})
+
+	path := filepath.Join(t.TempDir(), "build.log")
+	if err := ioutil.WriteFile(path, []byte("exit status 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	comment, err := c.AttachFile(ID(task.ID), path, "")
+	if err != nil {
+		t.Fatalf("Failed to attach the file: %s", err)
+	}
+
+	f := comment.Attachment
+	if f == nil || f.FileName != "build.log" || f.FileSize != 14 || comment.Content != "build.log" {
+		t.Fatalf("Failed to attach the file: got %+v, %+v", comment, f)
+	}
+
+	req, _ := http.NewRequest("GET", f.FileURL, nil)
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to download the attachment: %s", err)
+	}
+	defer resp.Body.Close()
+	if data, _ := ioutil.ReadAll(resp.Body); string(data) != "exit status 1\n" {
+		t.Fatalf("Unexpected attachment content %q", data)
+	}
+
+	list, err := c.ListCommentsWithFilter(&CommentFilter{TaskID: ID(task.ID)})
+	if err != nil {
+		t.Fatalf("Failed to list comments: %s", err)
+	}
+	if len(list) != 1 || list[0].Attachment == nil || list[0].Attachment.FileURL != f.FileURL {
+		t.Fatalf("Expected the attachment in the listed comment, got %+v", list)
+	}
+
+	if _, err := c.AttachFile(ID(task.ID), filepath.Join(t.TempDir(), "missing"), ""); !os.IsNotExist(err) {
+		t.Fatalf("Expected a missing file error, got %v", err)
+	}
+}
+
+func TestAttachmentString(t *testing.T) {
+	tests := []struct {
+		attachment Attachment
+		want       string
+	}{
+		{Attachment{FileName: "a.txt"}, "a.txt"},
+		{Attachment{FileName: "a.txt", FileSize: 512}, "a.txt (512 B)"},
+		{Attachment{FileName: "a.png", FileType: "image/png", FileSize: 3 << 20}, "a.png (image/png, 3.0 MiB)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.attachment.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestUploadFileName(t *testing.T) {
+	c, _ := newTestClient(t)
+	if _, err := c.UploadFile("", strings.NewReader("data")); err == nil {
+		t.Fatal("Expected a validation error for an upload without name")
+	}
+}
+
+func TestUnifiedUpload(t *testing.T) {
+	c, s := newUnifiedTestClient(t)
+	task := s.AddTask(todoisttest.Task{Content: "attached"})
+
+	attachment, err := c.UploadFile("notes.txt", strings.NewReader("notes"))
+	if err != nil {
+		t.Fatalf("Failed to upload a file: %s", err)
+	}
+	comment, err := c.AddComment(&AddCommentParams{TaskID: ID(task.ID), Content: "file", Attachment: attachment})
+	if err != nil {
+		t.Fatalf("Failed to attach the file: %s", err)
+	}
+	if comment.Attachment == nil || comment.Attachment.FileName != "notes.txt" {
+		t.Fatalf("Failed to attach the file: got %+v", comment)
+	}
+}
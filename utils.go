@@ -1,6 +1,7 @@
 package todoist
 
 import (
+	"fmt"
 	"regexp"
 )
 
@@ -18,3 +19,22 @@ func sanitizeLink(content string) string {
 func marginLink(content string) string {
 	return link1.ReplaceAllString(content, "[$1]( $2 )")
 }
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}